@@ -1,15 +1,26 @@
 package cache
 
 import (
+	"context"
+	"math/rand"
 	"runtime"
+	"sync"
 	"time"
 )
 
 const (
 	// NoExpiration 永不过期
 	NoExpiration time.Duration = 0
+	// NoTTL TTL对永不过期的对象返回的剩余过期时长，与即将过期的对象区分，同Redis的TTL命令返回-1
+	NoTTL time.Duration = -1
 	// DefaultCleanInterval 默认的清空缓存时长
 	DefaultCleanInterval time.Duration = time.Minute
+	// DefaultMemoryCheckInterval 默认的内存检查时间间隔
+	DefaultMemoryCheckInterval time.Duration = time.Second
+	// DefaultMemoryGCRounds 默认每次内存检查最多强制执行GC的次数
+	DefaultMemoryGCRounds = 1
+	// DefaultHotKeyDecayInterval 默认的热点key访问次数衰减时间间隔
+	DefaultHotKeyDecayInterval time.Duration = time.Minute
 )
 
 // Cache 缓存器
@@ -18,10 +29,108 @@ type Cache interface {
 	Set(key string, val interface{})
 	// SetWithExpiration 缓存一个对象，并设置过期时间
 	SetWithExpiration(key string, val interface{}, expiration time.Duration)
+	// SetWithTags 缓存一个对象，并设置过期时间和标签
+	SetWithTags(key string, val interface{}, expiration time.Duration, tags ...string)
+	// SetWithOptions 缓存一个对象，并设置过期时间、优先级、固定等选项
+	SetWithOptions(key string, val interface{}, opts ItemOptions)
+	// InvalidateTag 删除包含标签的所有缓存对象，返回删除的数量
+	InvalidateTag(tag string) int
+	// DeletePrefix 删除key以prefix开头的所有缓存对象，返回删除的数量
+	DeletePrefix(prefix string) int
+	// SetUntil 缓存一个对象，并在deadline时间点过期
+	SetUntil(key string, val interface{}, deadline time.Time)
 	// Get 获取一个缓存对象
 	Get(key string) (value interface{}, found bool)
+	// Peek 获取一个缓存对象，不会改变LRU顺序，也不会删除过期对象
+	Peek(key string) (value interface{}, found bool)
+	// Contains 缓存对象是否存在，不会改变LRU顺序，也不会删除过期对象
+	Contains(key string) bool
+	// Inspect 获取一个缓存对象的元信息，不会改变LRU顺序，也不会删除过期对象
+	Inspect(key string) (info ItemInfo, found bool)
+	// GetWithVersion 获取一个缓存对象及其版本号
+	GetWithVersion(key string) (value interface{}, version uint64, found bool)
+	// SetIfVersion 仅当缓存对象的版本号与version一致时覆盖缓存对象，并设置过期时间
+	// 缓存对象不存在或版本号不一致时返回false
+	SetIfVersion(key string, val interface{}, version uint64, expiration time.Duration) bool
+	// Resize 修改LRU缓存的容量，超出新容量的对象会被立即淘汰并调用删除回调
+	// 无容量上限的缓存返回ErrNotBounded
+	Resize(capacity int) error
+	// SetDefaultExpiration 修改默认过期时长，只影响之后写入的对象
+	SetDefaultExpiration(expiration time.Duration)
+	// SetCleanInterval 修改自动清理时间间隔并重启cleaner，不大于0时停止自动清理
+	// 命名空间与父缓存共享同一个cleaner，缓存Close后调用不做任何事
+	SetCleanInterval(interval time.Duration)
 	// Delete 删除一个缓存对象
 	Delete(key string)
+	// TTL 获取缓存对象的剩余过期时长，永不过期的对象返回NoTTL，不会改变LRU顺序
+	TTL(key string) (ttl time.Duration, found bool)
+	// Expire 重新设置缓存对象的过期时长，返回false表示对象不存在
+	Expire(key string, expiration time.Duration) bool
+	// ExpireAt 重新设置缓存对象的过期时间点，返回false表示对象不存在
+	ExpireAt(key string, expiredTime time.Time) bool
+	// Persist 将缓存对象设置为永不过期，返回false表示对象不存在
+	Persist(key string) bool
+	// Touch 将缓存对象的过期时长重置为默认过期时长，返回false表示对象不存在
+	Touch(key string) bool
+	// GetMulti 批量获取缓存对象，返回的map中只包含存在的对象
+	GetMulti(keys []string) map[string]interface{}
+	// SetMulti 批量缓存对象，并设置过期时间
+	SetMulti(items map[string]interface{}, expiration time.Duration)
+	// DeleteMulti 批量删除缓存对象
+	DeleteMulti(keys []string)
+	// IncrementInt64 原子地将整数类型的缓存对象加上delta，并返回新的值，缓存对象保持原有的类型
+	// 对象不存在时以delta为初始值创建对象，并设置过期时间；对象存在时保留原有的过期时间
+	// 对象是浮点数时返回ErrTypeMismatch，结果超出对象类型的取值范围时返回ErrOverflow
+	IncrementInt64(key string, delta int64, expiration time.Duration) (int64, error)
+	// IncrementFloat64 原子地将浮点数类型的缓存对象加上delta，用法同IncrementInt64，对象是整数时返回ErrTypeMismatch
+	IncrementFloat64(key string, delta float64, expiration time.Duration) (float64, error)
+	// DecrementInt64 原子地将整数类型的缓存对象减去delta，用法同IncrementInt64
+	DecrementInt64(key string, delta int64, expiration time.Duration) (int64, error)
+	// DecrementFloat64 原子地将浮点数类型的缓存对象减去delta，用法同IncrementInt64
+	DecrementFloat64(key string, delta float64, expiration time.Duration) (float64, error)
+	// Update 原子地修改缓存对象，fn的参数为当前的缓存对象及其是否存在
+	// fn返回新的缓存对象，keep为false时删除缓存对象；对象存在时保留原有的过期时间，否则使用默认过期时间
+	// 返回修改后的缓存对象及其是否存在
+	// fn执行期间持有该key的锁，不会阻塞其他key的读写，不要在fn中操作缓存
+	Update(key string, fn func(old interface{}, exists bool) (new interface{}, keep bool)) (value interface{}, found bool)
+	// Compute 同Update，但总是使用expiration作为新的过期时间
+	Compute(key string, fn func(old interface{}, exists bool) (new interface{}, keep bool), expiration time.Duration) (value interface{}, found bool)
+	// ComputeIfAbsent 缓存对象不存在时调用fn创建对象，并设置过期时间，keep为false时不创建
+	// 返回当前的缓存对象及其是否存在
+	ComputeIfAbsent(key string, fn func() (new interface{}, keep bool), expiration time.Duration) (value interface{}, found bool)
+	// ComputeIfPresent 缓存对象存在时调用fn修改对象，并保留原有的过期时间，keep为false时删除对象
+	// 返回修改后的缓存对象及其是否存在
+	ComputeIfPresent(key string, fn func(old interface{}) (new interface{}, keep bool)) (value interface{}, found bool)
+	// Stats 获取统计信息
+	Stats() Stats
+	// HotKeys 返回最近访问次数最多的n个key，按估计的访问次数从高到低排序，包括未命中的key
+	// 需要设置Options.HotKeyCapacity，未启用时返回nil；命名空间拥有独立的热点key统计
+	HotKeys(n int) []HotKey
+	// Subscribe 订阅缓存事件，bufferSize为事件通道的缓冲区大小，调用cancel取消订阅并关闭事件通道
+	// 订阅者处理过慢时，事件会按照filter.Overflow的策略丢弃，不会阻塞缓存的写操作
+	Subscribe(filter EventFilter, bufferSize int) (events <-chan Event, cancel func())
+	// Namespace 获取命名空间，命名空间中的key会自动加上前缀，以避免与其他命名空间冲突
+	// 命名空间拥有独立的Len、Range、Flush和统计信息，但与父缓存共享容量、淘汰策略和cleaner协程
+	// 父缓存的视图包含所有命名空间（包括嵌套的命名空间）中的对象：Len和统计信息计入这些对象，
+	// Range、Scan、Subscribe等会看到"name\x00key"形式的原始key，Flush会清空所有命名空间
+	// 名称中的"\x00"会被转义，key中不应包含"\x00"，否则会被当作命名空间中的对象统计
+	Namespace(name string) Cache
+	// GetCtx 获取一个缓存对象，ctx已取消时返回ctx.Err()
+	GetCtx(ctx context.Context, key string) (value interface{}, found bool, err error)
+	// SetCtx 缓存一个对象，ctx已取消时不缓存并返回ctx.Err()
+	SetCtx(ctx context.Context, key string, val interface{}) error
+	// SetWithExpirationCtx 缓存一个对象并设置过期时间，ctx已取消时不缓存并返回ctx.Err()
+	SetWithExpirationCtx(ctx context.Context, key string, val interface{}, expiration time.Duration) error
+	// DeleteCtx 删除一个缓存对象，ctx已取消时不删除并返回ctx.Err()
+	DeleteCtx(ctx context.Context, key string) error
+	// GetOrLoadCtx 获取一个缓存对象，不存在时调用loader加载并设置过期时间，loader返回错误时不缓存
+	// 同一个key的并发加载只会调用一次loader，等待加载时ctx被取消会立即返回ctx.Err()
+	// loader收到的context保留ctx中的值和第一个调用方的截止时间，部分调用方离开时加载继续进行，
+	// 所有等待的调用方都因ctx取消而离开时loader的context被取消，结果不会被缓存，之后的调用会重新加载
+	GetOrLoadCtx(ctx context.Context, key string, loader LoaderFunc, expiration time.Duration) (interface{}, error)
+	// Close 停止缓存的后台协程，之后仍然可以读写缓存，但不会再自动清理过期对象
+	// 命名空间的Close不做任何事
+	Close()
 	// 实现ItemMap接口的所有方法
 	ItemMap
 }
@@ -29,16 +138,53 @@ type Cache interface {
 // DeletedCallback 缓存对象被删除时的回调函数
 type DeletedCallback func(string, interface{})
 
+// LoadObserver 观察GetOrLoadCtx的加载耗时和结果
+type LoadObserver func(key string, d time.Duration, err error)
+
 // Options 缓存选项
-// @DefaultExpiration 默认的过期时长
-// @CleanInterval 自动清理时间间隔
-// @Capacity 容量，设置后将启用LRU
+// @DefaultExpiration 默认的过期时长，可以通过SetDefaultExpiration修改
+// @CleanInterval 自动清理时间间隔，可以通过SetCleanInterval修改
+// @Capacity 容量，设置后将启用LRU，可以通过Resize修改
 // @DeletedCallback 缓存对象被删除时的回调函数
+// @ExpirationJitter 过期时间的随机抖动上限，对象的过期时间会随机提前[0, ExpirationJitter)，避免大量对象同时过期
+// @KeyIndex 启用有序的key索引，加速RangePrefix、RangeBetween和DeletePrefix
+// @CallbackWorkers 执行删除回调的工作协程数量，为0时在释放锁后同步执行删除回调
+// @CallbackQueueSize 删除回调的队列长度，队列已满时删除操作会阻塞，默认等于CallbackWorkers
+// @ErrorHandler 删除回调发生panic时的处理函数，为nil时输出到标准日志
+// @MaxPinned LRU缓存中固定对象的数量上限，超过时淘汰最久未访问的固定对象，为0时不限制
+// @MemoryLimit 堆内存软上限(字节)，超过时按优先级从低到高、最久未访问优先的顺序淘汰未固定的对象，为0时不检查
+// @MemoryLowWatermark 超过软上限时淘汰对象直到堆内存低于该值，默认为MemoryLimit的90%
+// @MemoryCheckInterval 检查堆内存的时间间隔，默认为DefaultMemoryCheckInterval
+// @MemoryGCRounds 每次检查最多执行的淘汰轮数，每轮淘汰后强制执行一次GC并重新采样，默认为DefaultMemoryGCRounds
+// @Clock 计算过期时间和访问时间使用的时钟，默认使用系统时间
+// @HotKeyCapacity 使用count-min sketch统计访问频率并记录的热点key数量，为0时不统计
+// @HotKeyDecayInterval 热点key的访问次数减半的时间间隔，使排名反映最近的访问情况，默认为DefaultHotKeyDecayInterval
+// @Interceptors 拦截Get、Set、Delete、Range、Update几类操作的拦截器，按顺序执行，第一个拦截器在最外层，拦截的方法见Interceptor
+// @LoadObserver GetOrLoadCtx每次调用loader后执行，参数为key、加载耗时和loader返回的错误，可用于记录加载延迟
 type Options struct {
 	DefaultExpiration time.Duration
 	CleanInterval     time.Duration
 	Capacity          int
 	DeletedCallback   DeletedCallback
+	ExpirationJitter  time.Duration
+	KeyIndex          bool
+	CallbackWorkers   int
+	CallbackQueueSize int
+	ErrorHandler      func(error)
+	MaxPinned         int
+
+	MemoryLimit         uint64
+	MemoryLowWatermark  uint64
+	MemoryCheckInterval time.Duration
+	MemoryGCRounds      int
+
+	Clock Clock
+
+	HotKeyCapacity      int
+	HotKeyDecayInterval time.Duration
+
+	Interceptors []Interceptor
+	LoadObserver LoadObserver
 }
 
 // New 新建缓存器
@@ -52,60 +198,384 @@ func NewWithOptions(options *Options) Cache {
 		options = &Options{}
 	}
 
+	callbacks := newCallbackDispatcher(options)
+
 	var m ItemMap
 	if options.Capacity <= 0 {
 		// 无容量上限的缓存
-		m = newItemMap(options.DeletedCallback)
+		m = newItemMap(options, callbacks)
 	} else {
 		// LRU缓存
-		m = newLRUItemMap(options.Capacity, options.DeletedCallback)
+		m = newLRUItemMap(options, callbacks)
 	}
 
 	c := &cache{
-		ItemMap: m,
-		options: options,
+		ItemMap:  m,
+		options:  options,
+		clock:    clockOf(options),
+		counters: newNamespaceCounters(),
 	}
-	if options.CleanInterval > 0 {
-		// 启动cleaner协程
-		cleaner := newCleaner(c, options.CleanInterval)
-		// 创建包装器
-		wapper := &cacheWapper{c, cleaner}
-		runtime.SetFinalizer(wapper, cacheFinalizer)
-		return wapper
+	c.usage = c.counters.root
+	c.hotKeys = newHotKeyTracker(options, c.now())
+	m.(baseItemMap).addIndex(c.counters)
+	// 启动cleaner协程
+	c.settings = newSettings(c, options)
+
+	// 创建包装器，cleaner可能在运行时启动，所以总是需要包装器
+	wapper := &cacheWapper{Cache: newInterceptedCache(c, options.Interceptors), settings: c.settings, callbacks: callbacks}
+	if options.MemoryLimit > 0 {
+		// 启动内存检查协程
+		wapper.memory = newMemoryMonitor(c, options)
 	}
-	return c
+	runtime.SetFinalizer(wapper, cacheFinalizer)
+	return wapper
 }
 
 var _ Cache = &cache{}
 
 // cache 缓存器，不暴露给外部使用
 type cache struct {
+	// stats 包含64位原子操作的字段，放在结构体开头以保证64位对齐
+	stats statsCounter
 	ItemMap
-	options *Options
+	options  *Options
+	settings *settings
+	clock    Clock
+	// usage 统计成本之和以及被淘汰和过期删除的次数
+	usage *prefixCounter
+	// counters 所有命名空间的计数器，与父缓存共享
+	counters *namespaceCounters
+	loads    loadGroup
+	// hotKeys 热点key统计，未启用时为nil
+	hotKeys *hotKeyTracker
+
+	nsMu       sync.Mutex
+	namespaces map[string]*cache
 }
 
 func (c *cache) Set(key string, val interface{}) {
-	expiration := NoExpiration
-	if c.options != nil {
-		expiration = c.options.DefaultExpiration
-	}
-	c.SetWithExpiration(key, val, expiration)
+	c.SetWithExpiration(key, val, c.defaultExpiration())
 }
 
 func (c *cache) SetWithExpiration(key string, val interface{}, expiration time.Duration) {
-	c.AddItem(key, NewItem(val, expiration))
+	c.AddItem(key, c.newItem(val, expiration))
+}
+
+func (c *cache) SetWithTags(key string, val interface{}, expiration time.Duration, tags ...string) {
+	item := c.newItem(val, expiration)
+	item.Tags = tags
+	c.AddItem(key, item)
+}
+
+func (c *cache) InvalidateTag(tag string) int {
+	return c.RemoveItemsByTag(tag)
+}
+
+func (c *cache) DeletePrefix(prefix string) int {
+	return c.RemoveItemsByPrefix(prefix)
+}
+
+func (c *cache) SetWithOptions(key string, val interface{}, opts ItemOptions) {
+	item := c.newItem(val, opts.TTL)
+	item.Priority = opts.Priority
+	item.Pinned = opts.Pinned
+	item.Tags = opts.Tags
+	item.Cost = opts.Cost
+	c.AddItem(key, item)
+}
+
+func (c *cache) SetUntil(key string, val interface{}, deadline time.Time) {
+	c.AddItem(key, NewItemWithDeadline(val, c.withJitter(deadline)))
 }
 
 func (c *cache) Get(key string) (value interface{}, found bool) {
+	now := c.now()
+	item, ok := c.getItem(key, now)
+	c.recordAccess(key, item, ok, now)
+	if !ok {
+		return nil, false
+	}
+	return item.Value, true
+}
+
+func (c *cache) Peek(key string) (value interface{}, found bool) {
+	item, ok := c.peekItem(key)
+	if !ok {
+		return nil, false
+	}
+	return item.Value, true
+}
+
+func (c *cache) Contains(key string) bool {
+	_, ok := c.peekItem(key)
+	return ok
+}
+
+func (c *cache) Inspect(key string) (info ItemInfo, found bool) {
+	item, ok := c.peekItem(key)
+	if !ok {
+		return ItemInfo{}, false
+	}
+	return item.info(), true
+}
+
+func (c *cache) GetWithVersion(key string) (value interface{}, version uint64, found bool) {
+	now := c.now()
+	item, ok := c.getItem(key, now)
+	c.recordAccess(key, item, ok, now)
+	if !ok {
+		return nil, 0, false
+	}
+	return item.Value, item.Version, true
+}
+
+func (c *cache) SetIfVersion(key string, val interface{}, version uint64, expiration time.Duration) bool {
+	return c.AddItemIfVersion(key, c.newItem(val, expiration), version)
+}
+
+func (c *cache) TTL(key string) (ttl time.Duration, found bool) {
+	now := c.now()
+	item, ok := c.PeekItem(key)
+	if !ok || item.isExpiredAt(now) {
+		return 0, false
+	}
+	if item.ExpiredTime == nil {
+		return NoTTL, true
+	}
+	return item.ExpiredTime.Sub(now), true
+}
+
+func (c *cache) Expire(key string, expiration time.Duration) bool {
+	return c.updateExpiredTime(key, func() *time.Time {
+		return c.expiredTime(expiration)
+	})
+}
+
+func (c *cache) ExpireAt(key string, expiredTime time.Time) bool {
+	return c.updateExpiredTime(key, func() *time.Time {
+		deadline := c.withJitter(expiredTime)
+		return &deadline
+	})
+}
+
+func (c *cache) Persist(key string) bool {
+	return c.updateExpiredTime(key, func() *time.Time {
+		return nil
+	})
+}
+
+func (c *cache) Touch(key string) bool {
+	return c.Expire(key, c.defaultExpiration())
+}
+
+// updateExpiredTime 原子地修改缓存对象的过期时间
+func (c *cache) updateExpiredTime(key string, fn func() *time.Time) bool {
+	found := false
+	_ = c.UpdateItem(key, func(old *Item) (*Item, error) {
+		if old == nil {
+			return nil, nil
+		}
+		found = true
+		item := old.clone()
+		item.ExpiredTime = fn()
+		return item, nil
+	})
+	return found
+}
+
+func (c *cache) GetMulti(keys []string) map[string]interface{} {
+	items := c.GetItems(keys)
+	values := make(map[string]interface{}, len(items))
+	var expiredKeys []string
+	now := c.now()
+	for key, item := range items {
+		if item.isExpiredAt(now) {
+			expiredKeys = append(expiredKeys, key)
+			continue
+		}
+		item.touch(now)
+		values[key] = item.Value
+	}
+	if len(expiredKeys) > 0 {
+		c.RemoveItems(expiredKeys)
+	}
+	if c.hotKeys != nil {
+		for _, key := range keys {
+			c.hotKeys.record(key, now)
+		}
+	}
+	c.stats.recordMulti(len(values), len(keys)-len(values))
+	return values
+}
+
+func (c *cache) SetMulti(items map[string]interface{}, expiration time.Duration) {
+	newItems := make(map[string]*Item, len(items))
+	for key, val := range items {
+		newItems[key] = c.newItem(val, expiration)
+	}
+	c.AddItems(newItems)
+}
+
+func (c *cache) DeleteMulti(keys []string) {
+	c.RemoveItems(keys)
+}
+
+func (c *cache) Update(key string, fn func(old interface{}, exists bool) (new interface{}, keep bool)) (value interface{}, found bool) {
+	return c.compute(key, func(old *Item) *Item {
+		if old == nil {
+			val, keep := fn(nil, false)
+			if !keep {
+				return nil
+			}
+			return c.newItem(val, c.defaultExpiration())
+		}
+		val, keep := fn(old.Value, true)
+		if !keep {
+			return nil
+		}
+		item := old.clone()
+		item.Value = val
+		return item
+	})
+}
+
+func (c *cache) Compute(key string, fn func(old interface{}, exists bool) (new interface{}, keep bool), expiration time.Duration) (value interface{}, found bool) {
+	return c.compute(key, func(old *Item) *Item {
+		if old == nil {
+			val, keep := fn(nil, false)
+			if !keep {
+				return nil
+			}
+			return c.newItem(val, expiration)
+		}
+		val, keep := fn(old.Value, true)
+		if !keep {
+			return nil
+		}
+		item := old.clone()
+		item.Value = val
+		item.ExpiredTime = c.expiredTime(expiration)
+		return item
+	})
+}
+
+func (c *cache) ComputeIfAbsent(key string, fn func() (new interface{}, keep bool), expiration time.Duration) (value interface{}, found bool) {
+	return c.compute(key, func(old *Item) *Item {
+		if old != nil {
+			return old
+		}
+		val, keep := fn()
+		if !keep {
+			return nil
+		}
+		return c.newItem(val, expiration)
+	})
+}
+
+func (c *cache) ComputeIfPresent(key string, fn func(old interface{}) (new interface{}, keep bool)) (value interface{}, found bool) {
+	return c.compute(key, func(old *Item) *Item {
+		if old == nil {
+			return nil
+		}
+		val, keep := fn(old.Value)
+		if !keep {
+			return nil
+		}
+		item := old.clone()
+		item.Value = val
+		return item
+	})
+}
+
+// compute 原子地修改缓存项，fn返回nil表示删除缓存项
+func (c *cache) compute(key string, fn func(old *Item) *Item) (interface{}, bool) {
+	var result *Item
+	_ = c.UpdateItem(key, func(old *Item) (*Item, error) {
+		result = fn(old)
+		return result, nil
+	})
+	if result == nil {
+		return nil, false
+	}
+	return result.Value, true
+}
+
+// newItem 新建缓存项，过期时间会加上随机抖动
+// 永不过期时不读取时钟，创建时间在写入时设置；否则过期时间和创建时间使用同一次读取的时间
+func (c *cache) newItem(val interface{}, expiration time.Duration) *Item {
+	if expiration == NoExpiration {
+		return &Item{Value: val}
+	}
+	now := c.now()
+	return &Item{
+		Value:       val,
+		ExpiredTime: c.expiredTimeAt(now, expiration),
+		CreatedTime: now,
+	}
+}
+
+// expiredTime 根据过期时长计算加上随机抖动后的过期时间，永不过期时返回nil
+func (c *cache) expiredTime(expiration time.Duration) *time.Time {
+	if expiration == NoExpiration {
+		return nil
+	}
+	return c.expiredTimeAt(c.now(), expiration)
+}
+
+// expiredTimeAt 计算从now开始经过expiration后加上随机抖动的过期时间
+func (c *cache) expiredTimeAt(now time.Time, expiration time.Duration) *time.Time {
+	expiredTime := c.withJitter(now.Add(expiration))
+	return &expiredTime
+}
+
+// withJitter 将过期时间随机提前[0, ExpirationJitter)，且不早于当前时间
+func (c *cache) withJitter(deadline time.Time) time.Time {
+	if c.options == nil || c.options.ExpirationJitter <= 0 {
+		return deadline
+	}
+	jitter := c.options.ExpirationJitter
+	if remaining := deadline.Sub(c.now()); remaining < jitter {
+		jitter = remaining
+	}
+	if jitter <= 0 {
+		return deadline
+	}
+	return deadline.Add(-time.Duration(rand.Int63n(int64(jitter))))
+}
+
+func (c *cache) defaultExpiration() time.Duration {
+	return c.settings.getDefaultExpiration()
+}
+
+// recordAccess 记录一次访问
+func (c *cache) recordAccess(key string, item *Item, hit bool, now time.Time) {
+	c.stats.record(hit)
+	if hit {
+		item.touch(now)
+	}
+	c.hotKeys.record(key, now)
+}
+
+// peekItem 获取未过期的缓存项，不会改变LRU顺序，也不会删除过期对象
+func (c *cache) peekItem(key string) (*Item, bool) {
+	item, ok := c.PeekItem(key)
+	if !ok || item.isExpiredAt(c.now()) {
+		return nil, false
+	}
+	return item, true
+}
+
+// getItem 获取在now时间点未过期的缓存项，过期的缓存项会被删除
+func (c *cache) getItem(key string, now time.Time) (*Item, bool) {
 	item, ok := c.GetItem(key)
 	if !ok {
 		return nil, false
 	}
-	if item.IsExpired() {
+	if item.isExpiredAt(now) {
 		c.RemoveItem(key)
 		return nil, false
 	}
-	return item.Value, true
+	return item, true
 }
 
 func (c *cache) Delete(key string) {
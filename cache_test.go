@@ -1,8 +1,10 @@
 package cache_test
 
 import (
+	"fmt"
 	"math/rand"
 	"runtime"
+	"sync"
 	"testing"
 	"time"
 
@@ -226,3 +228,413 @@ func TestCacheWithCallback(t *testing.T) {
 	c.Flush()
 	assert.Equal(t, count, 0)
 }
+
+func TestCacheVersionExpired(t *testing.T) {
+	// 已过期但尚未清理的对象视为不存在，无法CAS
+	testFunc := func(t *testing.T, capacity int) {
+		clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+		c, _ := cache.NewCache(cache.WithCapacity(capacity), cache.WithClock(clock))
+		c.SetWithExpiration("key", 1, time.Minute)
+		_, version, _ := c.GetWithVersion("key")
+		clock.Advance(2 * time.Minute)
+		assert.Equal(t, c.SetIfVersion("key", 2, version, cache.NoExpiration), false)
+		_, found := c.Get("key")
+		assert.Equal(t, found, false)
+	}
+	t.Run("Cache", func(t *testing.T) { testFunc(t, 0) })
+	t.Run("LRUCache", func(t *testing.T) { testFunc(t, 10) })
+}
+
+func TestCacheVersion(t *testing.T) {
+	c := cache.New()
+
+	// 不存在的对象无法CAS
+	ok := c.SetIfVersion("key", 1, 0, cache.NoExpiration)
+	assert.Equal(t, ok, false)
+
+	c.Set("key", 1)
+	value, version, found := c.GetWithVersion("key")
+	assert.Equal(t, found, true)
+	assert.Equal(t, value, 1)
+
+	// 版本号一致，写入成功且版本号递增
+	ok = c.SetIfVersion("key", 2, version, cache.NoExpiration)
+	assert.Equal(t, ok, true)
+	value, newVersion, _ := c.GetWithVersion("key")
+	assert.Equal(t, value, 2)
+	assert.True(t, newVersion > version)
+
+	// 使用旧版本号写入失败
+	ok = c.SetIfVersion("key", 3, version, cache.NoExpiration)
+	assert.Equal(t, ok, false)
+	value, _ = c.Get("key")
+	assert.Equal(t, value, 2)
+
+	// Set会更新版本号
+	c.Set("key", 4)
+	_, version, _ = c.GetWithVersion("key")
+	assert.True(t, version > newVersion)
+
+	// 遍历时可以获取版本号
+	c.RangeItems(func(key string, info cache.ItemInfo) bool {
+		assert.Equal(t, key, "key")
+		assert.Equal(t, info.Value, 4)
+		assert.Equal(t, info.Version, version)
+		return true
+	})
+}
+
+func TestCacheUpdate(t *testing.T) {
+	testFunc := func(t *testing.T, c cache.Cache) {
+		// 对象不存在时创建
+		value, found := c.Update("key", func(old interface{}, exists bool) (interface{}, bool) {
+			assert.Equal(t, exists, false)
+			return []int{1}, true
+		})
+		assert.Equal(t, found, true)
+		assert.Equal(t, value, []int{1})
+
+		// 追加元素
+		value, found = c.Update("key", func(old interface{}, exists bool) (interface{}, bool) {
+			assert.Equal(t, exists, true)
+			return append(old.([]int), 2), true
+		})
+		assert.Equal(t, found, true)
+		assert.Equal(t, value, []int{1, 2})
+
+		// keep为false时删除对象
+		_, found = c.Update("key", func(old interface{}, exists bool) (interface{}, bool) {
+			return nil, false
+		})
+		assert.Equal(t, found, false)
+		_, found = c.Get("key")
+		assert.Equal(t, found, false)
+		assert.Equal(t, c.Len(), 0)
+
+		// ComputeIfPresent不会创建对象
+		_, found = c.ComputeIfPresent("key", func(old interface{}) (interface{}, bool) {
+			t.Error("fn should not be called")
+			return nil, true
+		})
+		assert.Equal(t, found, false)
+
+		// ComputeIfAbsent只会创建一次
+		value, found = c.ComputeIfAbsent("key", func() (interface{}, bool) {
+			return 1, true
+		}, cache.NoExpiration)
+		assert.Equal(t, found, true)
+		assert.Equal(t, value, 1)
+		value, _ = c.ComputeIfAbsent("key", func() (interface{}, bool) {
+			t.Error("fn should not be called")
+			return 2, true
+		}, cache.NoExpiration)
+		assert.Equal(t, value, 1)
+
+		// Compute设置新的过期时间
+		c.Compute("key", func(old interface{}, exists bool) (interface{}, bool) {
+			return old.(int) + 1, true
+		}, time.Hour)
+		item, _ := c.GetItem("key")
+		assert.Equal(t, item.Value, 2)
+		assert.NotNil(t, item.ExpiredTime)
+
+		// Update保留原有的过期时间
+		expiredTime := *item.ExpiredTime
+		c.Update("key", func(old interface{}, exists bool) (interface{}, bool) {
+			return old.(int) + 1, true
+		})
+		item, _ = c.GetItem("key")
+		assert.Equal(t, item.Value, 3)
+		assert.Equal(t, *item.ExpiredTime, expiredTime)
+
+		// 并发修改不会丢失写入
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					c.Update("slice", func(old interface{}, exists bool) (interface{}, bool) {
+						if !exists {
+							return []int{i}, true
+						}
+						return append(old.([]int), i), true
+					})
+				}
+			}(i)
+		}
+		wg.Wait()
+		value, _ = c.Get("slice")
+		assert.Equal(t, len(value.([]int)), 1000)
+
+		// fn执行期间不会阻塞其他key的读写，同一个key的写操作等待fn完成
+		entered := make(chan struct{})
+		release := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			c.Update("slow", func(old interface{}, exists bool) (interface{}, bool) {
+				close(entered)
+				<-release
+				return 1, true
+			})
+		}()
+		<-entered
+		c.Set("other", 1)
+		value, _ = c.Get("other")
+		assert.Equal(t, value, 1)
+		go func() {
+			time.Sleep(time.Millisecond * 10)
+			close(release)
+		}()
+		c.Update("slow", func(old interface{}, exists bool) (interface{}, bool) {
+			assert.Equal(t, old, 1)
+			return old.(int) + 1, true
+		})
+		<-done
+		value, _ = c.Get("slow")
+		assert.Equal(t, value, 2)
+	}
+
+	t.Run("Cache", func(t *testing.T) {
+		testFunc(t, cache.New())
+	})
+	t.Run("LRUCache", func(t *testing.T) {
+		testFunc(t, cache.NewWithOptions(&cache.Options{Capacity: 10}))
+	})
+}
+
+func TestCacheMulti(t *testing.T) {
+	testFunc := func(t *testing.T, c cache.Cache) {
+		c.SetMulti(map[string]interface{}{
+			"key1": 1,
+			"key2": 2,
+			"key3": 3,
+		}, cache.NoExpiration)
+		assert.Equal(t, c.Len(), 3)
+
+		// 不存在的key不会出现在结果中
+		values := c.GetMulti([]string{"key1", "key2", "key4"})
+		assert.Equal(t, values, map[string]interface{}{"key1": 1, "key2": 2})
+
+		c.DeleteMulti([]string{"key1", "key3", "key4"})
+		assert.Equal(t, c.Len(), 1)
+		values = c.GetMulti([]string{"key1", "key2", "key3"})
+		assert.Equal(t, values, map[string]interface{}{"key2": 2})
+
+		// 过期对象不会出现在结果中
+		c.SetMulti(map[string]interface{}{"key5": 5}, time.Millisecond*100)
+		time.Sleep(time.Millisecond * 200)
+		values = c.GetMulti([]string{"key2", "key5"})
+		assert.Equal(t, values, map[string]interface{}{"key2": 2})
+		assert.Equal(t, c.Len(), 1)
+	}
+
+	t.Run("Cache", func(t *testing.T) {
+		testFunc(t, cache.New())
+	})
+	t.Run("LRUCache", func(t *testing.T) {
+		testFunc(t, cache.NewWithOptions(&cache.Options{Capacity: 10}))
+	})
+}
+
+func TestCacheTTL(t *testing.T) {
+	testFunc := func(t *testing.T, c cache.Cache) {
+		_, found := c.TTL("key")
+		assert.Equal(t, found, false)
+		assert.Equal(t, c.Expire("key", time.Second), false)
+		assert.Equal(t, c.Persist("key"), false)
+		assert.Equal(t, c.Touch("key"), false)
+
+		c.SetWithExpiration("key", 1, time.Minute)
+		ttl, found := c.TTL("key")
+		assert.Equal(t, found, true)
+		assert.True(t, ttl > time.Second*59 && ttl <= time.Minute)
+
+		// 永不过期
+		assert.Equal(t, c.Persist("key"), true)
+		ttl, _ = c.TTL("key")
+		assert.Equal(t, ttl, cache.NoTTL)
+
+		// 重新设置过期时长，值保持不变
+		assert.Equal(t, c.Expire("key", time.Hour), true)
+		ttl, _ = c.TTL("key")
+		assert.True(t, ttl > time.Minute*59 && ttl <= time.Hour)
+		value, _ := c.Get("key")
+		assert.Equal(t, value, 1)
+
+		// 设置过期时间点
+		assert.Equal(t, c.ExpireAt("key", time.Now().Add(time.Millisecond*100)), true)
+		time.Sleep(time.Millisecond * 200)
+		_, found = c.Get("key")
+		assert.Equal(t, found, false)
+
+		// 重置为默认过期时长
+		c.SetWithExpiration("key", 1, time.Millisecond*100)
+		assert.Equal(t, c.Touch("key"), true)
+		time.Sleep(time.Millisecond * 200)
+		ttl, found = c.TTL("key")
+		assert.Equal(t, found, true)
+		assert.True(t, ttl > time.Minute*59)
+	}
+
+	options := &cache.Options{DefaultExpiration: time.Hour}
+	t.Run("Cache", func(t *testing.T) {
+		testFunc(t, cache.NewWithOptions(options))
+	})
+	t.Run("LRUCache", func(t *testing.T) {
+		options := *options
+		options.Capacity = 10
+		testFunc(t, cache.NewWithOptions(&options))
+	})
+}
+
+func TestCacheSetUntil(t *testing.T) {
+	c := cache.New()
+
+	deadline := time.Now().Add(time.Millisecond * 500)
+	c.SetUntil("key", 1, deadline)
+	item, found := c.GetItem("key")
+	assert.Equal(t, found, true)
+	assert.Equal(t, *item.ExpiredTime, deadline)
+
+	time.Sleep(time.Until(deadline) + time.Millisecond*100)
+	_, found = c.Get("key")
+	assert.Equal(t, found, false)
+}
+
+func TestCacheExpirationJitter(t *testing.T) {
+	jitter := time.Second * 10
+	options := &cache.Options{
+		ExpirationJitter: jitter,
+	}
+	c := cache.NewWithOptions(options)
+
+	// 过期时间随机提前，且不超过抖动上限
+	deadline := time.Now().Add(time.Minute)
+	expiredTimes := make(map[time.Time]bool)
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("key%d", i)
+		c.SetUntil(key, i, deadline)
+		item, _ := c.GetItem(key)
+		assert.False(t, item.ExpiredTime.After(deadline))
+		assert.True(t, item.ExpiredTime.After(deadline.Add(-jitter)))
+		expiredTimes[*item.ExpiredTime] = true
+	}
+	assert.True(t, len(expiredTimes) > 1)
+
+	// 抖动不会使过期时间早于当前时间
+	c.SetWithExpiration("short", 1, time.Millisecond*100)
+	item, _ := c.GetItem("short")
+	assert.False(t, item.ExpiredTime.Before(time.Now().Add(-time.Millisecond)))
+
+	// 永不过期的对象不受影响
+	c.Set("forever", 1)
+	item, _ = c.GetItem("forever")
+	assert.Nil(t, item.ExpiredTime)
+}
+
+func TestCacheInspect(t *testing.T) {
+	c := cache.New()
+
+	_, found := c.Inspect("key")
+	assert.Equal(t, found, false)
+
+	c.SetWithExpiration("key", 1, time.Hour)
+	info, found := c.Inspect("key")
+	assert.Equal(t, found, true)
+	assert.Equal(t, info.Value, 1)
+	assert.NotNil(t, info.ExpiredTime)
+	assert.False(t, info.CreatedTime.IsZero())
+	assert.True(t, info.AccessedTime.IsZero())
+	assert.Equal(t, info.Hits, uint64(0))
+
+	// Get会记录访问，Inspect不会
+	_, _ = c.Get("key")
+	_, _ = c.Get("key")
+	info, _ = c.Inspect("key")
+	assert.Equal(t, info.Hits, uint64(2))
+	assert.False(t, info.AccessedTime.IsZero())
+
+	// 修改对象时保留创建时间和访问次数
+	createdTime := info.CreatedTime
+	c.Update("key", func(old interface{}, exists bool) (interface{}, bool) {
+		return 2, true
+	})
+	info, _ = c.Inspect("key")
+	assert.Equal(t, info.CreatedTime, createdTime)
+	assert.Equal(t, info.Hits, uint64(2))
+
+	// 遍历时可以获取元信息
+	c.RangeItems(func(key string, info cache.ItemInfo) bool {
+		assert.Equal(t, info.Value, 2)
+		assert.Equal(t, info.Hits, uint64(2))
+		return true
+	})
+
+	// Inspect不会删除过期对象
+	c.SetWithExpiration("expired", 1, time.Millisecond*100)
+	time.Sleep(time.Millisecond * 200)
+	_, found = c.Inspect("expired")
+	assert.Equal(t, found, false)
+	assert.Equal(t, c.Len(), 2)
+}
+
+func TestSetDefaultExpiration(t *testing.T) {
+	c := cache.NewWithOptions(&cache.Options{DefaultExpiration: time.Hour})
+
+	c.Set("key1", 1)
+	c.SetDefaultExpiration(time.Minute)
+	c.Set("key2", 2)
+
+	ttl, _ := c.TTL("key1")
+	assert.True(t, ttl > time.Minute)
+	ttl, _ = c.TTL("key2")
+	assert.True(t, ttl <= time.Minute)
+
+	// 命名空间与父缓存共享默认过期时长
+	ns := c.Namespace("ns")
+	c.SetDefaultExpiration(cache.NoExpiration)
+	ns.Set("key3", 3)
+	ttl, _ = ns.TTL("key3")
+	assert.Equal(t, ttl, cache.NoTTL)
+}
+
+func TestSetCleanInterval(t *testing.T) {
+	c := cache.New()
+	c.SetWithExpiration("key", 1, time.Millisecond*10)
+
+	// 启动自动清理
+	c.SetCleanInterval(time.Millisecond * 10)
+	assert.Eventually(t, func() bool {
+		return c.Len() == 0
+	}, time.Second, time.Millisecond*10)
+
+	// 停止自动清理
+	c.SetCleanInterval(0)
+	c.SetWithExpiration("key", 1, time.Millisecond*10)
+	time.Sleep(time.Millisecond * 50)
+	assert.Equal(t, c.Len(), 1)
+
+	// 并发修改
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c.SetCleanInterval(time.Millisecond * time.Duration(i+1))
+			c.SetDefaultExpiration(time.Second * time.Duration(i))
+			c.Set(fmt.Sprint(i), i)
+		}(i)
+	}
+	wg.Wait()
+	c.SetCleanInterval(0)
+
+	// Close之后不再启动自动清理
+	c.Close()
+	c.SetCleanInterval(time.Millisecond * 10)
+	c.SetWithExpiration("closed", 1, time.Millisecond*10)
+	n := c.Len()
+	time.Sleep(time.Millisecond * 50)
+	assert.Equal(t, c.Len(), n)
+}
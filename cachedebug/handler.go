@@ -0,0 +1,137 @@
+package cachedebug
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	cache "github.com/Nomango/go-cache"
+)
+
+const (
+	// defaultHotKeys 默认展示的热点key数量
+	defaultHotKeys = 10
+	// defaultPageSize 默认每页展示的key数量
+	defaultPageSize = 100
+)
+
+// Handler 返回展示注册表中所有缓存的调试页面，类似/debug/pprof
+//
+//	/                         所有缓存的大小、命中率等统计信息
+//	/?cache=name              缓存的统计信息、热点key和key浏览器
+//	    &n=10                 热点key的数量
+//	    &match=user:*         按glob模式搜索key
+//	    &cursor=0&count=100   分页遍历key
+//	    &format=json          返回JSON
+//
+// 页面只读取缓存对象的元信息，不会改变LRU顺序，也不会展示缓存对象的值
+func Handler(r *cache.Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		q := req.URL.Query()
+		asJSON := q.Get("format") == "json"
+
+		name := q.Get("cache")
+		if name == "" {
+			render(w, asJSON, indexTemplate, Snapshot(r))
+			return
+		}
+
+		c, ok := r.Lookup(name)
+		if !ok {
+			http.Error(w, "cache not found: "+name, http.StatusNotFound)
+			return
+		}
+		page := cachePage{
+			CacheStats: statsOf(name, c),
+			Match:      q.Get("match"),
+		}
+		page.HotKeys = HotKeys(c, intParam(q.Get("n"), defaultHotKeys))
+		cursor, _ := strconv.ParseUint(q.Get("cursor"), 10, 64)
+		page.Keys, page.Next = Keys(c, cursor, page.Match, intParam(q.Get("count"), defaultPageSize))
+		if page.Next != 0 {
+			page.NextURL = nextPageURL(q, page.Next)
+		}
+		render(w, asJSON, cacheTemplate, page)
+	})
+}
+
+// cachePage 单个缓存的页面数据
+type cachePage struct {
+	CacheStats
+	HotKeys []KeyInfo
+	Match   string
+	Keys    []KeyInfo
+	Next    uint64
+	NextURL string `json:"-"`
+}
+
+// nextPageURL 下一页的链接，保留n、match、count等所有查询参数，只替换cursor
+func nextPageURL(q url.Values, next uint64) string {
+	params := url.Values{}
+	for k, v := range q {
+		params[k] = v
+	}
+	params.Set("cursor", strconv.FormatUint(next, 10))
+	params.Del("format")
+	return "?" + params.Encode()
+}
+
+func render(w http.ResponseWriter, asJSON bool, tmpl *template.Template, data interface{}) {
+	if asJSON {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(data)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := tmpl.Execute(w, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func intParam(s string, def int) int {
+	if n, err := strconv.Atoi(s); err == nil && n > 0 {
+		return n
+	}
+	return def
+}
+
+var indexTemplate = template.Must(template.New("index").Parse(`<html>
+<head><title>caches</title></head>
+<body>
+<h1>caches</h1>
+<table border="1">
+<tr><th>name</th><th>len</th><th>hits</th><th>misses</th><th>hit ratio</th><th>evictions</th><th>memory evictions</th><th>expirations</th><th>cost</th></tr>
+{{range .}}<tr>
+<td><a href="?cache={{.Name}}">{{.Name}}</a></td><td>{{.Len}}</td><td>{{.Hits}}</td><td>{{.Misses}}</td><td>{{printf "%.4f" .HitRatio}}</td>
+<td>{{.Evictions}}</td><td>{{.MemoryEvictions}}</td><td>{{.Expirations}}</td><td>{{.Cost}}</td>
+</tr>{{end}}
+</table>
+</body>
+</html>
+`))
+
+var cacheTemplate = template.Must(template.New("cache").Parse(`{{define "keys"}}<table border="1">
+<tr><th>key</th><th>type</th><th>hits</th><th>frequency</th><th>cost</th><th>priority</th><th>pinned</th><th>tags</th><th>created</th><th>accessed</th><th>expires</th></tr>
+{{range .}}<tr>
+<td>{{.Key}}</td><td>{{.Type}}</td><td>{{.Hits}}</td><td>{{.Frequency}}</td><td>{{.Cost}}</td><td>{{.Priority}}</td><td>{{.Pinned}}</td><td>{{.Tags}}</td>
+<td>{{.CreatedTime.Format "2006-01-02 15:04:05"}}</td>
+<td>{{if not .AccessedTime.IsZero}}{{.AccessedTime.Format "2006-01-02 15:04:05"}}{{end}}</td>
+<td>{{with .ExpiredTime}}{{.Format "2006-01-02 15:04:05"}}{{end}}</td>
+</tr>{{end}}
+</table>{{end}}<html>
+<head><title>{{.Name}}</title></head>
+<body>
+<p><a href="?">caches</a></p>
+<h1>{{.Name}}</h1>
+<p>len {{.Len}}, hits {{.Hits}}, misses {{.Misses}}, hit ratio {{printf "%.4f" .HitRatio}}, evictions {{.Evictions}}, memory evictions {{.MemoryEvictions}}, expirations {{.Expirations}}, cost {{.Cost}}</p>
+<h2>hot keys</h2>
+{{template "keys" .HotKeys}}
+<h2>keys</h2>
+<form><input type="hidden" name="cache" value="{{.Name}}"><input name="match" value="{{.Match}}" placeholder="glob pattern"><input type="submit" value="search"></form>
+{{template "keys" .Keys}}
+{{if .NextURL}}<p><a href="{{.NextURL}}">next</a></p>{{end}}
+</body>
+</html>
+`))
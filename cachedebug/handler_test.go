@@ -0,0 +1,161 @@
+package cachedebug_test
+
+import (
+	"encoding/json"
+	"expvar"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	cache "github.com/Nomango/go-cache"
+	"github.com/Nomango/go-cache/cachedebug"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRegistry() *cache.Registry {
+	r := cache.NewRegistry()
+	_ = r.Register("users", &cache.Options{Capacity: 3})
+	_ = r.Register("orders", nil)
+
+	users := r.MustGet("users")
+	users.Set("user:1", 1)
+	users.Set("user:2", 2)
+	users.Set("user:3", 3)
+	for i := 0; i < 3; i++ {
+		_, _ = users.Get("user:2")
+	}
+	_, _ = users.Get("user:3")
+	_, _ = users.Get("none")
+	return r
+}
+
+func get(t *testing.T, h http.Handler, url string, v interface{}) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+	if v != nil {
+		assert.Equal(t, json.Unmarshal(rec.Body.Bytes(), v), nil)
+	}
+	return rec
+}
+
+func TestHandler(t *testing.T) {
+	r := newRegistry()
+	h := cachedebug.Handler(r)
+
+	var stats []cachedebug.CacheStats
+	get(t, h, "/?format=json", &stats)
+	assert.Equal(t, len(stats), 2)
+	assert.Equal(t, stats[1].Name, "users")
+	assert.Equal(t, stats[1].Len, 3)
+	assert.Equal(t, stats[1].Hits, uint64(4))
+	assert.Equal(t, stats[1].HitRatio, 0.8)
+
+	var page struct {
+		Name    string
+		HotKeys []cachedebug.KeyInfo
+		Keys    []cachedebug.KeyInfo
+	}
+	get(t, h, "/?cache=users&n=2&match=user:[12]&format=json", &page)
+	assert.Equal(t, page.Name, "users")
+	assert.Equal(t, len(page.HotKeys), 2)
+	assert.Equal(t, page.HotKeys[0].Key, "user:2")
+	assert.Equal(t, page.HotKeys[0].Hits, uint64(3))
+	assert.Equal(t, page.HotKeys[1].Key, "user:3")
+	assert.Equal(t, len(page.Keys), 2)
+	assert.Equal(t, page.Keys[0].Type, "int")
+
+	// 调试页面不会改变LRU顺序，user:1仍然是最久未访问的对象
+	r.MustGet("users").Set("user:4", 4)
+	assert.Equal(t, r.MustGet("users").Contains("user:1"), false)
+
+	// HTML页面
+	rec := get(t, h, "/", nil)
+	assert.Equal(t, rec.Code, http.StatusOK)
+	assert.True(t, strings.Contains(rec.Body.String(), `href="?cache=users"`))
+	rec = get(t, h, "/?cache=users", nil)
+	assert.Equal(t, rec.Code, http.StatusOK)
+	assert.True(t, strings.Contains(rec.Body.String(), "user:2"))
+
+	rec = get(t, h, "/?cache=unknown", nil)
+	assert.Equal(t, rec.Code, http.StatusNotFound)
+}
+
+func TestHandlerNextPage(t *testing.T) {
+	r := newRegistry()
+	orders := r.MustGet("orders")
+	for i := 0; i < 10; i++ {
+		orders.Set("order:"+strconv.Itoa(i), i)
+	}
+	h := cachedebug.Handler(r)
+
+	// 下一页的链接保留所有查询参数
+	rec := get(t, h, "/?cache=orders&n=3&match=order:*&count=2", nil)
+	m := regexp.MustCompile(`href="([^"]*)">next<`).FindStringSubmatch(rec.Body.String())
+	assert.Equal(t, len(m), 2)
+	next, err := url.Parse(html.UnescapeString(m[1]))
+	assert.Equal(t, err, nil)
+	q := next.Query()
+	assert.Equal(t, q.Get("cache"), "orders")
+	assert.Equal(t, q.Get("n"), "3")
+	assert.Equal(t, q.Get("match"), "order:*")
+	assert.Equal(t, q.Get("count"), "2")
+	assert.True(t, q.Get("cursor") != "")
+
+	// 沿着链接遍历，每页的key数量不超过count
+	seen := make(map[string]bool)
+	for link := "/" + next.String(); ; {
+		var page struct {
+			Keys []cachedebug.KeyInfo
+			Next uint64
+		}
+		get(t, h, link+"&format=json", &page)
+		assert.True(t, len(page.Keys) <= 2)
+		for _, key := range page.Keys {
+			seen[key.Key] = true
+		}
+		rec = get(t, h, link, nil)
+		m = regexp.MustCompile(`href="([^"]*)">next<`).FindStringSubmatch(rec.Body.String())
+		if page.Next == 0 {
+			assert.Equal(t, len(m), 0)
+			break
+		}
+		link = "/" + html.UnescapeString(m[1])
+	}
+	assert.True(t, len(seen) >= 8)
+}
+
+func TestHotKeysTracked(t *testing.T) {
+	c := cache.NewWithOptions(&cache.Options{HotKeyCapacity: 10})
+	c.Set("a", 1)
+	c.Set("b", 2)
+	for i := 0; i < 5; i++ {
+		_, _ = c.Get("b")
+		_, _ = c.Get("missing")
+	}
+	_, _ = c.Get("a")
+
+	// 使用缓存的热点key统计，不包含不在缓存中的key
+	keys := cachedebug.HotKeys(c, 2)
+	assert.Equal(t, len(keys), 1)
+	assert.Equal(t, keys[0].Key, "b")
+	assert.Equal(t, keys[0].Frequency, uint64(5))
+	keys = cachedebug.HotKeys(c, 3)
+	assert.Equal(t, len(keys), 2)
+	assert.Equal(t, keys[1].Key, "a")
+}
+
+func TestPublish(t *testing.T) {
+	r := newRegistry()
+	cachedebug.Publish("caches", r)
+
+	var stats []cachedebug.CacheStats
+	assert.Equal(t, json.Unmarshal([]byte(expvar.Get("caches").String()), &stats), nil)
+	assert.Equal(t, len(stats), 2)
+	assert.Equal(t, stats[0].Name, "orders")
+	assert.Equal(t, stats[1].Len, 3)
+}
@@ -0,0 +1,157 @@
+// Package cachedebug 通过expvar和调试页面查看注册表中缓存的运行状态
+package cachedebug
+
+import (
+	"container/heap"
+	"expvar"
+	"fmt"
+	"time"
+
+	cache "github.com/Nomango/go-cache"
+)
+
+// CacheStats 缓存的统计信息
+type CacheStats struct {
+	Name     string
+	Len      int
+	HitRatio float64
+	cache.Stats
+}
+
+// KeyInfo 缓存对象的元信息，不包含缓存对象的值
+type KeyInfo struct {
+	Key          string
+	Type         string
+	Hits         uint64
+	Frequency    uint64
+	Cost         int64
+	Version      uint64
+	Priority     int
+	Pinned       bool
+	Tags         []string
+	CreatedTime  time.Time
+	AccessedTime time.Time
+	ExpiredTime  *time.Time
+}
+
+func newKeyInfo(key string, info cache.ItemInfo) KeyInfo {
+	return KeyInfo{
+		Key:          key,
+		Type:         fmt.Sprintf("%T", info.Value),
+		Hits:         info.Hits,
+		Cost:         info.Cost,
+		Version:      info.Version,
+		Priority:     info.Priority,
+		Pinned:       info.Pinned,
+		Tags:         info.Tags,
+		CreatedTime:  info.CreatedTime,
+		AccessedTime: info.AccessedTime,
+		ExpiredTime:  info.ExpiredTime,
+	}
+}
+
+// Snapshot 返回注册表中所有缓存的统计信息，按名称排序
+func Snapshot(r *cache.Registry) []CacheStats {
+	names := r.Names()
+	stats := make([]CacheStats, 0, len(names))
+	for _, name := range names {
+		if c, ok := r.Lookup(name); ok {
+			stats = append(stats, statsOf(name, c))
+		}
+	}
+	return stats
+}
+
+func statsOf(name string, c cache.Cache) CacheStats {
+	s := c.Stats()
+	return CacheStats{
+		Name:     name,
+		Len:      c.Len(),
+		HitRatio: s.HitRatio(),
+		Stats:    s,
+	}
+}
+
+// scanBatch 每次Scan遍历的key数量
+const scanBatch = 1000
+
+// HotKeys 返回最热的n个缓存对象
+// 缓存启用了热点key统计时，按最近估计的访问次数从高到低排序，并设置Frequency，不包含已不在缓存中的key
+// 否则使用Scan和Inspect遍历，按命中次数从高到低排序，不会改变LRU顺序，也不会长时间持有锁
+func HotKeys(c cache.Cache, n int) []KeyInfo {
+	if n <= 0 {
+		return nil
+	}
+	if hot := c.HotKeys(n); hot != nil {
+		infos := make([]KeyInfo, 0, len(hot))
+		for _, k := range hot {
+			if info, ok := c.Inspect(k.Key); ok {
+				ki := newKeyInfo(k.Key, info)
+				ki.Frequency = k.Count
+				infos = append(infos, ki)
+			}
+		}
+		return infos
+	}
+
+	h := &keyHeap{}
+	var cursor uint64
+	for {
+		var keys []string
+		keys, cursor = c.Scan(cursor, "", scanBatch)
+		for _, key := range keys {
+			info, ok := c.Inspect(key)
+			if !ok {
+				continue
+			}
+			if h.Len() < n {
+				heap.Push(h, newKeyInfo(key, info))
+			} else if info.Hits > (*h)[0].Hits {
+				(*h)[0] = newKeyInfo(key, info)
+				heap.Fix(h, 0)
+			}
+		}
+		if cursor == 0 {
+			break
+		}
+	}
+
+	result := make([]KeyInfo, h.Len())
+	for i := len(result) - 1; i >= 0; i-- {
+		result[i] = heap.Pop(h).(KeyInfo)
+	}
+	return result
+}
+
+// Keys 分批遍历匹配glob模式match的缓存对象，用法同Cache.Scan，不会改变LRU顺序
+func Keys(c cache.Cache, cursor uint64, match string, count int) ([]KeyInfo, uint64) {
+	keys, next := c.Scan(cursor, match, count)
+	infos := make([]KeyInfo, 0, len(keys))
+	for _, key := range keys {
+		if info, ok := c.Inspect(key); ok {
+			infos = append(infos, newKeyInfo(key, info))
+		}
+	}
+	return infos, next
+}
+
+// keyHeap 按命中次数排序的小顶堆
+type keyHeap []KeyInfo
+
+func (h keyHeap) Len() int            { return len(h) }
+func (h keyHeap) Less(i, j int) bool  { return h[i].Hits < h[j].Hits }
+func (h keyHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *keyHeap) Push(x interface{}) { *h = append(*h, x.(KeyInfo)) }
+func (h *keyHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// Publish 将注册表中所有缓存的统计信息以name发布到expvar，name重复时panic
+func Publish(name string, r *cache.Registry) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return Snapshot(r)
+	}))
+}
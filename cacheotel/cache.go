@@ -0,0 +1,203 @@
+// Package cacheotel 为缓存添加OpenTelemetry链路追踪和指标
+package cacheotel
+
+import (
+	"context"
+	"time"
+
+	cache "github.com/Nomango/go-cache"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName 追踪器和指标的名称
+const instrumentationName = "github.com/Nomango/go-cache/cacheotel"
+
+var (
+	nameKey = attribute.Key("cache.name")
+	keyKey  = attribute.Key("cache.key")
+	hitKey  = attribute.Key("cache.hit")
+)
+
+// Cache 带有链路追踪和指标的缓存，GetCtx、SetCtx等方法会创建span并记录指标，其他方法与被包装的缓存相同
+type Cache struct {
+	cache.Cache
+
+	name     string
+	withKeys bool
+	attrs    metric.MeasurementOption
+	tracer   trace.Tracer
+	hits     metric.Int64Counter
+	misses   metric.Int64Counter
+	loads    metric.Float64Histogram
+}
+
+// Option 包装选项
+type Option func(*config)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	withKeys       bool
+}
+
+// WithTracerProvider 设置TracerProvider，默认使用otel.GetTracerProvider()
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = tp
+	}
+}
+
+// WithMeterProvider 设置MeterProvider，默认使用otel.GetMeterProvider()
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = mp
+	}
+}
+
+// WithKeys 在span中记录缓存对象的key，key可能包含敏感信息，默认不记录
+func WithKeys() Option {
+	return func(c *config) {
+		c.withKeys = true
+	}
+}
+
+// Wrap 包装缓存，name用于区分不同的缓存
+func Wrap(c cache.Cache, name string, opts ...Option) (*Cache, error) {
+	cfg := config{
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	meter := cfg.meterProvider.Meter(instrumentationName)
+	hits, err := meter.Int64Counter("cache.hits", metric.WithDescription("Number of cache hits."))
+	if err != nil {
+		return nil, err
+	}
+	misses, err := meter.Int64Counter("cache.misses", metric.WithDescription("Number of cache misses."))
+	if err != nil {
+		return nil, err
+	}
+	loads, err := meter.Float64Histogram("cache.load.duration",
+		metric.WithDescription("Latency of loading values into the cache."), metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cache{
+		Cache:    c,
+		name:     name,
+		withKeys: cfg.withKeys,
+		attrs:    metric.WithAttributes(nameKey.String(name)),
+		tracer:   cfg.tracerProvider.Tracer(instrumentationName),
+		hits:     hits,
+		misses:   misses,
+		loads:    loads,
+	}, nil
+}
+
+// GetCtx 获取一个缓存对象，并创建cache.get span
+func (c *Cache) GetCtx(ctx context.Context, key string) (value interface{}, found bool, err error) {
+	ctx, span := c.start(ctx, "cache.get", key)
+	defer span.End()
+
+	value, found, err = c.Cache.GetCtx(ctx, key)
+	if err != nil {
+		c.recordError(span, err)
+		return nil, false, err
+	}
+	c.recordHit(ctx, span, found)
+	return value, found, nil
+}
+
+// SetCtx 缓存一个对象，并创建cache.set span
+func (c *Cache) SetCtx(ctx context.Context, key string, val interface{}) error {
+	ctx, span := c.start(ctx, "cache.set", key)
+	defer span.End()
+
+	err := c.Cache.SetCtx(ctx, key, val)
+	c.recordError(span, err)
+	return err
+}
+
+// SetWithExpirationCtx 缓存一个对象并设置过期时间，并创建cache.set span
+func (c *Cache) SetWithExpirationCtx(ctx context.Context, key string, val interface{}, expiration time.Duration) error {
+	ctx, span := c.start(ctx, "cache.set", key)
+	defer span.End()
+
+	err := c.Cache.SetWithExpirationCtx(ctx, key, val, expiration)
+	c.recordError(span, err)
+	return err
+}
+
+// DeleteCtx 删除一个缓存对象，并创建cache.delete span
+func (c *Cache) DeleteCtx(ctx context.Context, key string) error {
+	ctx, span := c.start(ctx, "cache.delete", key)
+	defer span.End()
+
+	err := c.Cache.DeleteCtx(ctx, key)
+	c.recordError(span, err)
+	return err
+}
+
+// GetOrLoadCtx 获取一个缓存对象，不存在时调用loader加载并以expiration缓存
+// 创建cache.get_or_load span，loader在其子span cache.load中执行，同一个key并发加载时只执行一次loader
+func (c *Cache) GetOrLoadCtx(ctx context.Context, key string, loader cache.LoaderFunc, expiration time.Duration) (interface{}, error) {
+	ctx, span := c.start(ctx, "cache.get_or_load", key)
+	defer span.End()
+
+	// 使用Peek判断是否命中，避免重复计入被包装缓存的命中统计
+	_, found := c.Cache.Peek(key)
+	c.recordHit(ctx, span, found)
+
+	value, err := c.Cache.GetOrLoadCtx(ctx, key, func(loadCtx context.Context) (interface{}, error) {
+		return c.load(loadCtx, key, loader)
+	}, expiration)
+	if err != nil {
+		c.recordError(span, err)
+		return nil, err
+	}
+	return value, nil
+}
+
+// load 在cache.load span中调用loader，并记录加载耗时
+func (c *Cache) load(ctx context.Context, key string, loader cache.LoaderFunc) (interface{}, error) {
+	ctx, span := c.start(ctx, "cache.load", key)
+	defer span.End()
+
+	start := time.Now()
+	value, err := loader(ctx)
+	c.loads.Record(ctx, time.Since(start).Seconds(), c.attrs)
+	c.recordError(span, err)
+	return value, err
+}
+
+func (c *Cache) start(ctx context.Context, spanName, key string) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{nameKey.String(c.name)}
+	if c.withKeys {
+		attrs = append(attrs, keyKey.String(key))
+	}
+	return c.tracer.Start(ctx, spanName, trace.WithSpanKind(trace.SpanKindInternal), trace.WithAttributes(attrs...))
+}
+
+func (c *Cache) recordHit(ctx context.Context, span trace.Span, hit bool) {
+	span.SetAttributes(hitKey.Bool(hit))
+	if hit {
+		c.hits.Add(ctx, 1, c.attrs)
+	} else {
+		c.misses.Add(ctx, 1, c.attrs)
+	}
+}
+
+func (c *Cache) recordError(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
@@ -0,0 +1,136 @@
+package cacheotel_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	cache "github.com/Nomango/go-cache"
+	"github.com/Nomango/go-cache/cacheotel"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+var _ cache.Cache = (*cacheotel.Cache)(nil)
+
+func newCache(t *testing.T) (*cacheotel.Cache, *tracetest.InMemoryExporter, *sdkmetric.ManualReader) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	c, err := cacheotel.Wrap(cache.New(), "users",
+		cacheotel.WithTracerProvider(tp), cacheotel.WithMeterProvider(mp), cacheotel.WithKeys())
+	assert.Equal(t, err, nil)
+	return c, exporter, reader
+}
+
+func attr(attrs []attribute.KeyValue, key string) attribute.Value {
+	for _, kv := range attrs {
+		if string(kv.Key) == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+// sum 返回计数器的值
+func sum(t *testing.T, reader *sdkmetric.ManualReader, name string) int64 {
+	var rm metricdata.ResourceMetrics
+	assert.Equal(t, reader.Collect(context.Background(), &rm), nil)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == name {
+				var total int64
+				for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+					total += dp.Value
+				}
+				return total
+			}
+		}
+	}
+	return 0
+}
+
+func TestGetSetCtx(t *testing.T) {
+	c, exporter, reader := newCache(t)
+	ctx := context.Background()
+
+	assert.Equal(t, c.SetCtx(ctx, "1", "user1"), nil)
+	v, ok, err := c.GetCtx(ctx, "1")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, v, "user1")
+	_, ok, _ = c.GetCtx(ctx, "2")
+	assert.Equal(t, ok, false)
+	assert.Equal(t, c.DeleteCtx(ctx, "1"), nil)
+
+	spans := exporter.GetSpans()
+	assert.Equal(t, len(spans), 4)
+	assert.Equal(t, spans[0].Name, "cache.set")
+	assert.Equal(t, attr(spans[0].Attributes, "cache.name").AsString(), "users")
+	assert.Equal(t, attr(spans[0].Attributes, "cache.key").AsString(), "1")
+	assert.Equal(t, spans[1].Name, "cache.get")
+	assert.Equal(t, attr(spans[1].Attributes, "cache.hit").AsBool(), true)
+	assert.Equal(t, attr(spans[2].Attributes, "cache.hit").AsBool(), false)
+	assert.Equal(t, spans[3].Name, "cache.delete")
+
+	assert.Equal(t, sum(t, reader, "cache.hits"), int64(1))
+	assert.Equal(t, sum(t, reader, "cache.misses"), int64(1))
+
+	// 已取消的ctx
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	exporter.Reset()
+	_, _, err = c.GetCtx(canceled, "1")
+	assert.Equal(t, err, context.Canceled)
+	spans = exporter.GetSpans()
+	assert.Equal(t, spans[0].Status.Code, codes.Error)
+}
+
+func TestGetOrLoadCtx(t *testing.T) {
+	c, exporter, reader := newCache(t)
+	ctx := context.Background()
+
+	loads := 0
+	loader := func(ctx context.Context) (interface{}, error) {
+		loads++
+		return "user1", nil
+	}
+	v, err := c.GetOrLoadCtx(ctx, "1", loader, time.Minute)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, v, "user1")
+	v, err = c.GetOrLoadCtx(ctx, "1", loader, time.Minute)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, v, "user1")
+	assert.Equal(t, loads, 1)
+
+	// 加载的span是get_or_load的子span
+	spans := exporter.GetSpans()
+	assert.Equal(t, len(spans), 3)
+	assert.Equal(t, spans[0].Name, "cache.load")
+	assert.Equal(t, spans[1].Name, "cache.get_or_load")
+	assert.Equal(t, spans[0].Parent.SpanID(), spans[1].SpanContext.SpanID())
+	assert.Equal(t, attr(spans[1].Attributes, "cache.hit").AsBool(), false)
+	assert.Equal(t, attr(spans[2].Attributes, "cache.hit").AsBool(), true)
+
+	assert.Equal(t, sum(t, reader, "cache.hits"), int64(1))
+	assert.Equal(t, sum(t, reader, "cache.misses"), int64(1))
+
+	// 加载失败
+	exporter.Reset()
+	_, err = c.GetOrLoadCtx(ctx, "2", func(ctx context.Context) (interface{}, error) {
+		return nil, errors.New("not found")
+	}, time.Minute)
+	assert.Equal(t, err.Error(), "not found")
+	spans = exporter.GetSpans()
+	assert.Equal(t, spans[0].Status.Code, codes.Error)
+	assert.Equal(t, spans[1].Status.Code, codes.Error)
+	assert.Equal(t, c.Contains("2"), false)
+}
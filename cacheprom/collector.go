@@ -0,0 +1,134 @@
+// Package cacheprom 将缓存的统计信息导出为Prometheus指标
+package cacheprom
+
+import (
+	"sync"
+	"time"
+
+	cache "github.com/Nomango/go-cache"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	namespace = "cache"
+	cacheKey  = "cache"
+)
+
+var _ prometheus.Collector = &Collector{}
+
+// Collector 导出一个或多个命名缓存的统计信息，每个缓存的指标以cache标签区分
+type Collector struct {
+	mu         sync.RWMutex
+	caches     map[string]cache.Cache
+	registries []*cache.Registry
+
+	hits        *prometheus.Desc
+	misses      *prometheus.Desc
+	evictions   *prometheus.Desc
+	expirations *prometheus.Desc
+	size        *prometheus.Desc
+	cost        *prometheus.Desc
+	loads       *prometheus.HistogramVec
+}
+
+// NewCollector 新建Collector
+func NewCollector() *Collector {
+	return &Collector{
+		caches: make(map[string]cache.Cache),
+		hits: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "hits_total"),
+			"Number of cache hits.", []string{cacheKey}, nil),
+		misses: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "misses_total"),
+			"Number of cache misses.", []string{cacheKey}, nil),
+		evictions: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "evictions_total"),
+			"Number of evicted entries by reason.", []string{cacheKey, "reason"}, nil),
+		expirations: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "expirations_total"),
+			"Number of entries removed after expiration.", []string{cacheKey}, nil),
+		size: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "size"),
+			"Number of entries in the cache.", []string{cacheKey}, nil),
+		cost: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "cost"),
+			"Total cost of entries in the cache.", []string{cacheKey}, nil),
+		loads: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "load_duration_seconds",
+			Help:      "Latency of loading values into the cache.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{cacheKey}),
+	}
+}
+
+// Add 添加一个命名缓存，name已存在时覆盖
+func (c *Collector) Add(name string, ca cache.Cache) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.caches[name] = ca
+}
+
+// Remove 移除一个命名缓存
+func (c *Collector) Remove(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.caches, name)
+}
+
+// AddRegistry 导出注册表中的所有缓存，每次采集时读取注册表，名称与Add添加的缓存冲突时以Add为准
+func (c *Collector) AddRegistry(r *cache.Registry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.registries = append(c.registries, r)
+}
+
+// ObserveLoad 记录一次加载的耗时，使用LoadObserver时GetOrLoadCtx的加载会自动记录，其他加载需要手动调用
+func (c *Collector) ObserveLoad(name string, d time.Duration) {
+	c.loads.WithLabelValues(name).Observe(d.Seconds())
+}
+
+// LoadObserver 返回记录加载耗时的观察函数，设置到缓存的Options.LoadObserver后GetOrLoadCtx的每次加载都会被记录
+func (c *Collector) LoadObserver(name string) cache.LoadObserver {
+	observer := c.loads.WithLabelValues(name)
+	return func(_ string, d time.Duration, _ error) {
+		observer.Observe(d.Seconds())
+	}
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.evictions
+	ch <- c.expirations
+	ch <- c.size
+	ch <- c.cost
+	c.loads.Describe(ch)
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	for name, ca := range c.snapshot() {
+		stats := ca.Stats()
+		ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(stats.Hits), name)
+		ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(stats.Misses), name)
+		ch <- prometheus.MustNewConstMetric(c.evictions, prometheus.CounterValue, float64(stats.Evictions), name, "capacity")
+		ch <- prometheus.MustNewConstMetric(c.evictions, prometheus.CounterValue, float64(stats.MemoryEvictions), name, "memory")
+		ch <- prometheus.MustNewConstMetric(c.expirations, prometheus.CounterValue, float64(stats.Expirations), name)
+		ch <- prometheus.MustNewConstMetric(c.size, prometheus.GaugeValue, float64(ca.Len()), name)
+		ch <- prometheus.MustNewConstMetric(c.cost, prometheus.GaugeValue, float64(stats.Cost), name)
+	}
+	c.loads.Collect(ch)
+}
+
+// snapshot 返回需要采集的所有缓存
+func (c *Collector) snapshot() map[string]cache.Cache {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	caches := make(map[string]cache.Cache, len(c.caches))
+	for _, r := range c.registries {
+		for _, name := range r.Names() {
+			if ca, ok := r.Lookup(name); ok {
+				caches[name] = ca
+			}
+		}
+	}
+	for name, ca := range c.caches {
+		caches[name] = ca
+	}
+	return caches
+}
@@ -0,0 +1,108 @@
+package cacheprom_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	cache "github.com/Nomango/go-cache"
+	"github.com/Nomango/go-cache/cacheprom"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollector(t *testing.T) {
+	users := cache.NewWithOptions(&cache.Options{Capacity: 2})
+	users.SetWithOptions("1", "user1", cache.ItemOptions{Cost: 3})
+	users.Set("2", "user2")
+	users.Set("3", "user3")
+	users.SetWithExpiration("3", "user3", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	users.ClearExpired()
+	_, _ = users.Get("2")
+	_, _ = users.Get("1")
+
+	c := cacheprom.NewCollector()
+	c.Add("users", users)
+	c.ObserveLoad("users", time.Millisecond*20)
+
+	expected := `
+# HELP cache_evictions_total Number of evicted entries by reason.
+# TYPE cache_evictions_total counter
+cache_evictions_total{cache="users",reason="capacity"} 1
+cache_evictions_total{cache="users",reason="memory"} 0
+# HELP cache_expirations_total Number of entries removed after expiration.
+# TYPE cache_expirations_total counter
+cache_expirations_total{cache="users"} 1
+# HELP cache_hits_total Number of cache hits.
+# TYPE cache_hits_total counter
+cache_hits_total{cache="users"} 1
+# HELP cache_misses_total Number of cache misses.
+# TYPE cache_misses_total counter
+cache_misses_total{cache="users"} 1
+# HELP cache_size Number of entries in the cache.
+# TYPE cache_size gauge
+cache_size{cache="users"} 1
+# HELP cache_cost Total cost of entries in the cache.
+# TYPE cache_cost gauge
+cache_cost{cache="users"} 0
+`
+	err := testutil.CollectAndCompare(c, strings.NewReader(expected),
+		"cache_evictions_total", "cache_expirations_total", "cache_hits_total",
+		"cache_misses_total", "cache_size", "cache_cost")
+	assert.Equal(t, err, nil)
+
+	// 加载耗时
+	assert.Equal(t, testutil.CollectAndCount(c, "cache_load_duration_seconds"), 1)
+}
+
+func TestCollectorLoadObserver(t *testing.T) {
+	c := cacheprom.NewCollector()
+	users := cache.NewWithOptions(&cache.Options{LoadObserver: c.LoadObserver("users")})
+	c.Add("users", users)
+
+	// GetOrLoadCtx的加载自动记录耗时，命中缓存时不记录
+	loader := func(ctx context.Context) (interface{}, error) {
+		return "user1", nil
+	}
+	_, _ = users.GetOrLoadCtx(context.Background(), "1", loader, cache.NoExpiration)
+	_, _ = users.GetOrLoadCtx(context.Background(), "1", loader, cache.NoExpiration)
+	_, _ = users.GetOrLoadCtx(context.Background(), "2", loader, cache.NoExpiration)
+
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(c)
+	families, err := reg.Gather()
+	assert.Equal(t, err, nil)
+	var count uint64
+	for _, f := range families {
+		if f.GetName() == "cache_load_duration_seconds" {
+			count = f.GetMetric()[0].GetHistogram().GetSampleCount()
+		}
+	}
+	assert.Equal(t, count, uint64(2))
+}
+
+func TestCollectorRegistry(t *testing.T) {
+	r := cache.NewRegistry()
+	assert.Equal(t, r.Register("a", nil), nil)
+	assert.Equal(t, r.Register("b", nil), nil)
+	r.MustGet("a").Set("key", 1)
+
+	c := cacheprom.NewCollector()
+	c.AddRegistry(r)
+
+	expected := `
+# HELP cache_size Number of entries in the cache.
+# TYPE cache_size gauge
+cache_size{cache="a"} 1
+cache_size{cache="b"} 0
+`
+	err := testutil.CollectAndCompare(c, strings.NewReader(expected), "cache_size")
+	assert.Equal(t, err, nil)
+
+	// 注册表中新增的缓存在下次采集时导出
+	assert.Equal(t, r.Register("c", nil), nil)
+	assert.Equal(t, testutil.CollectAndCount(c, "cache_size"), 3)
+}
@@ -0,0 +1,134 @@
+package cache
+
+import (
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync"
+)
+
+// CallbackPanicError 删除回调函数发生panic时报告的错误
+type CallbackPanicError struct {
+	Key       string
+	Recovered interface{}
+	Stack     []byte
+}
+
+func (e *CallbackPanicError) Error() string {
+	return fmt.Sprintf("cache: deleted callback panic on key %q: %v", e.Key, e.Recovered)
+}
+
+// deletedEntry 待调用删除回调的缓存对象
+type deletedEntry struct {
+	key   string
+	value interface{}
+}
+
+// callbackDispatcher 删除回调的分发器
+// 删除回调总是在释放锁之后调用，所以回调中可以操作缓存
+// 设置了工作协程数量时，回调在工作协程中异步执行，否则在调用方的协程中同步执行
+type callbackDispatcher struct {
+	cb      DeletedCallback
+	onError func(error)
+	queue   chan deletedEntry
+	done    chan struct{}
+	once    sync.Once
+}
+
+// newCallbackDispatcher 未设置删除回调时返回nil
+func newCallbackDispatcher(options *Options) *callbackDispatcher {
+	if options.DeletedCallback == nil {
+		return nil
+	}
+
+	d := &callbackDispatcher{
+		cb:      options.DeletedCallback,
+		onError: options.ErrorHandler,
+	}
+	if options.CallbackWorkers > 0 {
+		queueSize := options.CallbackQueueSize
+		if queueSize <= 0 {
+			queueSize = options.CallbackWorkers
+		}
+		d.queue = make(chan deletedEntry, queueSize)
+		d.done = make(chan struct{})
+		for i := 0; i < options.CallbackWorkers; i++ {
+			go d.work()
+		}
+	}
+	return d
+}
+
+// dispatch 分发删除回调，队列已满时阻塞直到有空闲的工作协程
+func (d *callbackDispatcher) dispatch(entries ...deletedEntry) {
+	for _, e := range entries {
+		if d.queue == nil {
+			d.call(e)
+			continue
+		}
+
+		select {
+		case d.queue <- e:
+		case <-d.done:
+			// 工作协程已停止
+			d.call(e)
+			continue
+		}
+
+		select {
+		case <-d.done:
+			// 工作协程可能已经退出，执行队列中剩余的回调
+			d.drain()
+		default:
+		}
+	}
+}
+
+func (d *callbackDispatcher) work() {
+	for {
+		select {
+		case e := <-d.queue:
+			d.call(e)
+		case <-d.done:
+			d.drain()
+			return
+		}
+	}
+}
+
+// drain 执行队列中剩余的回调
+func (d *callbackDispatcher) drain() {
+	for {
+		select {
+		case e := <-d.queue:
+			d.call(e)
+		default:
+			return
+		}
+	}
+}
+
+// call 调用删除回调，并捕获回调中发生的panic
+func (d *callbackDispatcher) call(e deletedEntry) {
+	defer func() {
+		if r := recover(); r != nil {
+			err := &CallbackPanicError{Key: e.key, Recovered: r, Stack: debug.Stack()}
+			if d.onError != nil {
+				d.onError(err)
+			} else {
+				log.Println(err)
+			}
+		}
+	}()
+	d.cb(e.key, e.value)
+}
+
+// stop 停止工作协程
+func (d *callbackDispatcher) stop() {
+	if d == nil || d.done == nil {
+		return
+	}
+	d.once.Do(func() {
+		close(d.done)
+	})
+}
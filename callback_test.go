@@ -0,0 +1,110 @@
+package cache_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Nomango/go-cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCallbackAccessCache(t *testing.T) {
+	testFunc := func(t *testing.T, capacity int) {
+		var c cache.Cache
+		deleted := make(chan string, 10)
+		c = cache.NewWithOptions(&cache.Options{
+			Capacity: capacity,
+			DeletedCallback: func(key string, v interface{}) {
+				// 回调中操作缓存不会死锁
+				c.Set("deleted:"+key, v)
+				deleted <- key
+			},
+		})
+
+		c.Set("key1", 1)
+		c.Delete("key1")
+		assert.Equal(t, <-deleted, "key1")
+
+		v, ok := c.Get("deleted:key1")
+		assert.Equal(t, ok, true)
+		assert.Equal(t, v, 1)
+	}
+	t.Run("Cache", func(t *testing.T) { testFunc(t, 0) })
+	t.Run("LRUCache", func(t *testing.T) { testFunc(t, 4) })
+}
+
+func TestCallbackPanic(t *testing.T) {
+	testFunc := func(t *testing.T, capacity int) {
+		var (
+			mu   sync.Mutex
+			errs []error
+		)
+		c := cache.NewWithOptions(&cache.Options{
+			Capacity: capacity,
+			DeletedCallback: func(key string, v interface{}) {
+				panic("boom")
+			},
+			ErrorHandler: func(err error) {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			},
+		})
+
+		c.Set("key1", 1)
+		c.Delete("key1")
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, len(errs), 1)
+
+		var perr *cache.CallbackPanicError
+		assert.True(t, errors.As(errs[0], &perr))
+		assert.Equal(t, perr.Key, "key1")
+		assert.Equal(t, perr.Recovered, "boom")
+	}
+	t.Run("Cache", func(t *testing.T) { testFunc(t, 0) })
+	t.Run("LRUCache", func(t *testing.T) { testFunc(t, 4) })
+}
+
+func TestCallbackWorkers(t *testing.T) {
+	testFunc := func(t *testing.T, capacity int) {
+		release := make(chan struct{})
+		deleted := make(chan string, 10)
+		c := cache.NewWithOptions(&cache.Options{
+			Capacity:          capacity,
+			CallbackWorkers:   1,
+			CallbackQueueSize: 4,
+			DeletedCallback: func(key string, v interface{}) {
+				<-release
+				deleted <- key
+			},
+		})
+
+		c.Set("key1", 1)
+		c.Set("key2", 2)
+
+		// 回调阻塞时不影响缓存的读写
+		done := make(chan struct{})
+		go func() {
+			c.Delete("key1")
+			c.Delete("key2")
+			_, ok := c.Get("key1")
+			assert.Equal(t, ok, false)
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("delete blocked by callback")
+		}
+
+		close(release)
+		assert.Equal(t, <-deleted, "key1")
+		assert.Equal(t, <-deleted, "key2")
+	}
+	t.Run("Cache", func(t *testing.T) { testFunc(t, 0) })
+	t.Run("LRUCache", func(t *testing.T) { testFunc(t, 4) })
+}
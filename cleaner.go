@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"sync"
 	"time"
 )
 
@@ -12,7 +13,10 @@ type cleaner struct {
 // cacheWapper 包装器，为了正确执行finalizer而使用
 type cacheWapper struct {
 	Cache
-	cleaner *cleaner
+	settings  *settings
+	callbacks *callbackDispatcher
+	memory    *memoryMonitor // 未设置MemoryLimit时为nil
+	closeOnce sync.Once
 }
 
 var _ Cache = &cacheWapper{}
@@ -44,6 +48,17 @@ func (c *cleaner) Stop() {
 	close(c.stopEvicter)
 }
 
+// Close 停止cleaner、删除回调的工作协程和内存检查协程
+func (c *cacheWapper) Close() {
+	c.closeOnce.Do(func() {
+		c.settings.close()
+		c.callbacks.stop()
+		if c.memory != nil {
+			c.memory.Stop()
+		}
+	})
+}
+
 func cacheFinalizer(c *cacheWapper) {
-	c.cleaner.Stop()
+	c.Close()
 }
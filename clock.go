@@ -0,0 +1,48 @@
+package cache
+
+import "time"
+
+// Clock 时钟，用于计算过期时间和访问时间，测试时可以替换为模拟时钟
+type Clock interface {
+	Now() time.Time
+}
+
+// systemClock 使用系统时间的时钟
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// clockOf 返回选项中的时钟，未设置时使用系统时间
+func clockOf(options *Options) Clock {
+	if options.Clock != nil {
+		return options.Clock
+	}
+	return systemClock{}
+}
+
+// clocked 使用时钟的缓存
+type clocked interface {
+	now() time.Time
+}
+
+// nowOf 返回缓存的时钟的当前时间
+func nowOf(c Cache) time.Time {
+	if cl, ok := c.(clocked); ok {
+		return cl.now()
+	}
+	return time.Now()
+}
+
+func (c *cache) now() time.Time {
+	return c.clock.Now()
+}
+
+func (w *cacheWapper) now() time.Time {
+	return nowOf(w.Cache)
+}
+
+func (w *namespaceWapper) now() time.Time {
+	return nowOf(w.Cache)
+}
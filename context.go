@@ -0,0 +1,180 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// LoaderFunc 缓存对象不存在时加载对象的函数
+type LoaderFunc func(ctx context.Context) (interface{}, error)
+
+// LoaderPanicError 加载函数发生panic时返回的错误
+type LoaderPanicError struct {
+	Key       string
+	Recovered interface{}
+}
+
+func (e *LoaderPanicError) Error() string {
+	return fmt.Sprintf("cache: loader panic on key %q: %v", e.Key, e.Recovered)
+}
+
+func (c *cache) GetCtx(ctx context.Context, key string) (value interface{}, found bool, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+	value, found = c.Get(key)
+	return value, found, nil
+}
+
+func (c *cache) SetCtx(ctx context.Context, key string, val interface{}) error {
+	return c.SetWithExpirationCtx(ctx, key, val, c.defaultExpiration())
+}
+
+func (c *cache) SetWithExpirationCtx(ctx context.Context, key string, val interface{}, expiration time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.SetWithExpiration(key, val, expiration)
+	return nil
+}
+
+func (c *cache) DeleteCtx(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.Delete(key)
+	return nil
+}
+
+func (c *cache) GetOrLoadCtx(ctx context.Context, key string, loader LoaderFunc, expiration time.Duration) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if value, found := c.Get(key); found {
+		return value, nil
+	}
+	return c.loadAndSet(ctx, key, loader, expiration, c.SetWithExpiration)
+}
+
+// loadAndSet 合并同一个key的并发加载，加载成功后使用set缓存结果
+func (c *cache) loadAndSet(ctx context.Context, key string, loader LoaderFunc, expiration time.Duration, set SetFunc) (interface{}, error) {
+	call := c.loads.do(ctx, key, func(loadCtx context.Context) (interface{}, error) {
+		start := time.Now()
+		value, err := c.load(loadCtx, key, loader)
+		if observer := c.options.LoadObserver; observer != nil {
+			observer(key, time.Since(start), err)
+		}
+		// 所有调用方都已离开时加载被放弃，之后的调用会重新加载，不缓存结果以免覆盖新的加载结果
+		if err == nil && loadCtx.Err() != context.Canceled {
+			set(key, value, expiration)
+		}
+		return value, err
+	})
+	select {
+	case <-call.done:
+		return call.value, call.err
+	case <-ctx.Done():
+		c.loads.leave(key, call)
+		return nil, ctx.Err()
+	}
+}
+
+// load 调用加载函数，加载函数发生panic时返回LoaderPanicError
+func (c *cache) load(ctx context.Context, key string, loader LoaderFunc) (value interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &LoaderPanicError{Key: key, Recovered: r}
+		}
+	}()
+	return loader(ctx)
+}
+
+// loadGroup 合并同一个key的并发加载
+type loadGroup struct {
+	mu    sync.Mutex
+	calls map[string]*loadCall
+}
+
+// loadCall 正在进行或已完成的加载
+type loadCall struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+
+	// cancel 取消传给加载函数的context
+	cancel context.CancelFunc
+	// waiters 等待加载结果的调用方数量，由loadGroup.mu保护
+	waiters int
+}
+
+// do 登记为key正在进行的加载的等待方，没有时在新的协程中执行fn
+// fn收到的context保留ctx中的值和截止时间，所有等待方离开时被取消
+func (g *loadGroup) do(ctx context.Context, key string, fn func(ctx context.Context) (interface{}, error)) *loadCall {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if call, ok := g.calls[key]; ok {
+		call.waiters++
+		return call
+	}
+	if g.calls == nil {
+		g.calls = make(map[string]*loadCall)
+	}
+	loadCtx, cancel := newLoadContext(ctx)
+	call := &loadCall{done: make(chan struct{}), cancel: cancel, waiters: 1}
+	g.calls[key] = call
+
+	go func() {
+		call.value, call.err = fn(loadCtx)
+
+		g.mu.Lock()
+		g.remove(key, call)
+		g.mu.Unlock()
+		cancel()
+		close(call.done)
+	}()
+	return call
+}
+
+// leave 等待方在加载完成前离开，最后一个等待方离开时取消加载，之后的调用会重新加载
+func (g *loadGroup) leave(key string, call *loadCall) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	call.waiters--
+	if call.waiters > 0 {
+		return
+	}
+	g.remove(key, call)
+	call.cancel()
+}
+
+// remove 删除key对应的加载，key已经开始新的加载时不做任何事
+func (g *loadGroup) remove(key string, call *loadCall) {
+	if g.calls[key] == call {
+		delete(g.calls, key)
+	}
+}
+
+// newLoadContext 新建传给加载函数的context，保留ctx中的值和截止时间，但不会因ctx取消而取消
+func newLoadContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	detached := detachedContext{ctx}
+	if deadline, ok := ctx.Deadline(); ok {
+		return context.WithDeadline(detached, deadline)
+	}
+	return context.WithCancel(detached)
+}
+
+// detachedContext 保留父context的值，但不会被取消，也没有截止时间
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
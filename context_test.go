@@ -0,0 +1,222 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Nomango/go-cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext(t *testing.T) {
+	testFunc := func(t *testing.T, c cache.Cache) {
+		ctx := context.Background()
+		assert.Equal(t, c.SetCtx(ctx, "key", 1), nil)
+		v, ok, err := c.GetCtx(ctx, "key")
+		assert.Equal(t, err, nil)
+		assert.Equal(t, ok, true)
+		assert.Equal(t, v, 1)
+
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, _, err = c.GetCtx(canceled, "key")
+		assert.Equal(t, err, context.Canceled)
+		assert.Equal(t, c.SetWithExpirationCtx(canceled, "key", 2, time.Minute), context.Canceled)
+		assert.Equal(t, c.DeleteCtx(canceled, "key"), context.Canceled)
+		v, _ = c.Get("key")
+		assert.Equal(t, v, 1)
+
+		assert.Equal(t, c.DeleteCtx(ctx, "key"), nil)
+		assert.Equal(t, c.Contains("key"), false)
+	}
+	t.Run("Cache", func(t *testing.T) { testFunc(t, cache.New()) })
+	t.Run("LRUCache", func(t *testing.T) { testFunc(t, cache.NewWithOptions(&cache.Options{Capacity: 10})) })
+}
+
+func TestGetOrLoadCtx(t *testing.T) {
+	c := cache.New()
+	ctx := context.Background()
+
+	// 并发加载同一个key只调用一次loader
+	var loads int32
+	release := make(chan struct{})
+	loader := func(ctx context.Context) (interface{}, error) {
+		atomic.AddInt32(&loads, 1)
+		<-release
+		return "value", nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := c.GetOrLoadCtx(ctx, "key", loader, time.Minute)
+			assert.Equal(t, err, nil)
+			assert.Equal(t, v, "value")
+		}()
+	}
+	time.Sleep(time.Millisecond * 10)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, atomic.LoadInt32(&loads), int32(1))
+
+	ttl, _ := c.TTL("key")
+	assert.True(t, ttl > 0 && ttl <= time.Minute)
+
+	// 加载失败时不缓存
+	_, err := c.GetOrLoadCtx(ctx, "error", func(ctx context.Context) (interface{}, error) {
+		return nil, errors.New("load failed")
+	}, time.Minute)
+	assert.Equal(t, err.Error(), "load failed")
+	assert.Equal(t, c.Contains("error"), false)
+
+	// 加载函数panic
+	_, err = c.GetOrLoadCtx(ctx, "panic", func(ctx context.Context) (interface{}, error) {
+		panic("boom")
+	}, time.Minute)
+	var perr *cache.LoaderPanicError
+	assert.True(t, errors.As(err, &perr))
+	assert.Equal(t, perr.Recovered, "boom")
+}
+
+func TestGetOrLoadCtxObserver(t *testing.T) {
+	type observation struct {
+		key string
+		err error
+	}
+	var observed []observation
+	var mu sync.Mutex
+	c := cache.NewWithOptions(&cache.Options{
+		LoadObserver: func(key string, d time.Duration, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			assert.True(t, d >= time.Millisecond)
+			observed = append(observed, observation{key, err})
+		},
+	})
+	ctx := context.Background()
+
+	loadErr := errors.New("load failed")
+	_, _ = c.GetOrLoadCtx(ctx, "key", func(ctx context.Context) (interface{}, error) {
+		time.Sleep(time.Millisecond)
+		return "value", nil
+	}, time.Minute)
+	_, _ = c.GetOrLoadCtx(ctx, "error", func(ctx context.Context) (interface{}, error) {
+		time.Sleep(time.Millisecond)
+		return nil, loadErr
+	}, time.Minute)
+	// 命中缓存时不调用loader，也不记录
+	_, _ = c.GetOrLoadCtx(ctx, "key", loadNothing, time.Minute)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, observed, []observation{{"key", nil}, {"error", loadErr}})
+}
+
+type ctxKey struct{}
+
+func TestGetOrLoadCtxCancel(t *testing.T) {
+	c := cache.New()
+	started := make(chan struct{})
+	loaded := make(chan error, 1)
+
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), ctxKey{}, "trace"), time.Second)
+	defer cancel()
+
+	// 阻塞的loader在所有调用方离开后被它的context释放
+	go func() {
+		<-started
+		cancel()
+	}()
+	_, err := c.GetOrLoadCtx(ctx, "key", func(ctx context.Context) (interface{}, error) {
+		// loader收到的context保留ctx中的值和截止时间
+		assert.Equal(t, ctx.Value(ctxKey{}), "trace")
+		_, ok := ctx.Deadline()
+		assert.Equal(t, ok, true)
+		close(started)
+		<-ctx.Done()
+		loaded <- ctx.Err()
+		return "value", nil
+	}, time.Minute)
+	assert.Equal(t, err, context.Canceled)
+	assert.Equal(t, <-loaded, context.Canceled)
+
+	// 被放弃的加载结果不会被缓存，之后的调用重新加载
+	time.Sleep(time.Millisecond * 10)
+	assert.Equal(t, c.Contains("key"), false)
+	v, err := c.GetOrLoadCtx(context.Background(), "key", func(ctx context.Context) (interface{}, error) {
+		return "reloaded", nil
+	}, time.Minute)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, v, "reloaded")
+
+	// 已取消的ctx不会触发加载
+	_, err = c.GetOrLoadCtx(ctx, "other", func(ctx context.Context) (interface{}, error) {
+		t.Fatal("should not load")
+		return nil, nil
+	}, time.Minute)
+	assert.Equal(t, err, context.Canceled)
+}
+
+func TestGetOrLoadCtxWaiters(t *testing.T) {
+	c := cache.New()
+	release := make(chan struct{})
+	started := make(chan struct{})
+	var once sync.Once
+	loader := func(ctx context.Context) (interface{}, error) {
+		once.Do(func() { close(started) })
+		select {
+		case <-release:
+			return "value", nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	// 第一个调用方离开后，其他调用方仍在等待，加载不会被取消
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		_, err := c.GetOrLoadCtx(ctx, "key", loader, time.Minute)
+		errs <- err
+	}()
+	<-started
+	result := make(chan interface{}, 1)
+	go func() {
+		v, _ := c.GetOrLoadCtx(context.Background(), "key", loader, time.Minute)
+		result <- v
+	}()
+	time.Sleep(time.Millisecond * 10)
+	cancel()
+	assert.Equal(t, <-errs, context.Canceled)
+
+	close(release)
+	assert.Equal(t, <-result, "value")
+	v, _ := c.Get("key")
+	assert.Equal(t, v, "value")
+}
+
+func loadNothing(ctx context.Context) (interface{}, error) {
+	return nil, nil
+}
+
+func TestContextAllocs(t *testing.T) {
+	c := cache.New()
+	ctx := context.Background()
+	c.Set("key", 1)
+
+	// 命中缓存时不分配内存
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _, _ = c.GetCtx(ctx, "key")
+	})
+	assert.Equal(t, allocs, float64(0))
+	allocs = testing.AllocsPerRun(100, func() {
+		_, _ = c.GetOrLoadCtx(ctx, "key", loadNothing, time.Minute)
+	})
+	assert.Equal(t, allocs, float64(0))
+}
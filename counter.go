@@ -0,0 +1,208 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+var (
+	// ErrNotNumeric 缓存对象不是数值
+	ErrNotNumeric = errors.New("cache: value is not numeric")
+	// ErrTypeMismatch 缓存对象是数值，但不能转换为指定的数值类型
+	ErrTypeMismatch = errors.New("cache: numeric type mismatch")
+	// ErrOverflow 运算结果超出缓存对象类型的取值范围
+	ErrOverflow = errors.New("cache: numeric overflow")
+)
+
+// Number 支持原子增减的数值类型
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// Increment 原子地将缓存对象加上delta，并返回新的值
+// 对象不存在时以delta为初始值创建对象，并设置过期时间；对象存在时保留原有的过期时间
+// 对象的类型必须是T，是其他数值类型时返回ErrTypeMismatch，不是数值时返回ErrNotNumeric，结果超出T的取值范围时返回ErrOverflow
+func Increment[T Number](c Cache, key string, delta T, expiration time.Duration) (T, error) {
+	return updateNumberOf(c, key, delta, false, expiration)
+}
+
+// Decrement 原子地将缓存对象减去delta，并返回新的值，用法同Increment
+// 对象不存在时以0减去delta为初始值，无符号类型的结果小于0时返回ErrOverflow
+func Decrement[T Number](c Cache, key string, delta T, expiration time.Duration) (T, error) {
+	return updateNumberOf(c, key, delta, true, expiration)
+}
+
+func updateNumberOf[T Number](c Cache, key string, delta T, sub bool, expiration time.Duration) (T, error) {
+	var result T
+	err := c.UpdateItem(key, func(old *Item) (*Item, error) {
+		var n T
+		if old != nil {
+			var ok bool
+			if n, ok = old.Value.(T); !ok {
+				return nil, typeError(key, old.Value, fmt.Sprintf("%T", result))
+			}
+		}
+		var ok bool
+		if result, ok = addNumber(n, delta, sub); !ok {
+			return nil, overflowError(key, n, delta, sub)
+		}
+		if old == nil {
+			return &Item{Value: result, ExpiredTime: expiredTimeOf(nowOf(c), expiration)}, nil
+		}
+		item := old.clone()
+		item.Value = result
+		return item, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return result, nil
+}
+
+// addNumber 计算n+delta，sub为true时计算n-delta，结果超出T的取值范围时ok为false
+func addNumber[T Number](n, delta T, sub bool) (result T, ok bool) {
+	if sub {
+		result = n - delta
+	} else {
+		result = n + delta
+	}
+	switch reflect.ValueOf(n).Kind() {
+	case reflect.Float32, reflect.Float64:
+		// 浮点数溢出时结果为无穷大
+		return result, !math.IsInf(float64(result), 0) || math.IsInf(float64(n), 0) || math.IsInf(float64(delta), 0)
+	}
+	if sub {
+		return result, !(delta > 0 && result > n) && !(delta < 0 && result < n)
+	}
+	return result, !(delta > 0 && result < n) && !(delta < 0 && result > n)
+}
+
+func (c *cache) IncrementInt64(key string, delta int64, expiration time.Duration) (int64, error) {
+	return c.updateInt64(key, delta, false, expiration)
+}
+
+func (c *cache) IncrementFloat64(key string, delta float64, expiration time.Duration) (float64, error) {
+	return c.updateFloat64(key, delta, false, expiration)
+}
+
+func (c *cache) DecrementInt64(key string, delta int64, expiration time.Duration) (int64, error) {
+	return c.updateInt64(key, delta, true, expiration)
+}
+
+func (c *cache) DecrementFloat64(key string, delta float64, expiration time.Duration) (float64, error) {
+	return c.updateFloat64(key, delta, true, expiration)
+}
+
+func (c *cache) updateInt64(key string, delta int64, sub bool, expiration time.Duration) (int64, error) {
+	var result int64
+	err := c.updateNumber(key, int64(0), expiration, func(v reflect.Value) (reflect.Value, error) {
+		nv := reflect.New(v.Type()).Elem()
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			var ok bool
+			result, ok = addNumber(v.Int(), delta, sub)
+			if !ok || v.OverflowInt(result) {
+				return nv, overflowError(key, v.Interface(), delta, sub)
+			}
+			nv.SetInt(result)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			// 按delta的绝对值计算，delta为负数时加减互换
+			// delta为math.MinInt64时-delta仍为math.MinInt64，转换为uint64后正好是它的绝对值
+			abs := uint64(delta)
+			if delta < 0 {
+				abs = uint64(-delta)
+			}
+			u, ok := addNumber(v.Uint(), abs, sub != (delta < 0))
+			if !ok || v.OverflowUint(u) || u > math.MaxInt64 {
+				return nv, overflowError(key, v.Interface(), delta, sub)
+			}
+			result = int64(u)
+			nv.SetUint(u)
+		default:
+			return nv, typeError(key, v.Interface(), "int64")
+		}
+		return nv, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return result, nil
+}
+
+func (c *cache) updateFloat64(key string, delta float64, sub bool, expiration time.Duration) (float64, error) {
+	var result float64
+	err := c.updateNumber(key, float64(0), expiration, func(v reflect.Value) (reflect.Value, error) {
+		nv := reflect.New(v.Type()).Elem()
+		switch v.Kind() {
+		case reflect.Float32, reflect.Float64:
+			var ok bool
+			result, ok = addNumber(v.Float(), delta, sub)
+			if !ok || v.OverflowFloat(result) {
+				return nv, overflowError(key, v.Interface(), delta, sub)
+			}
+			nv.SetFloat(result)
+		default:
+			// 整数类型的对象加上小数后无法保持原有的类型
+			return nv, typeError(key, v.Interface(), "float64")
+		}
+		return nv, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return result, nil
+}
+
+// updateNumber 原子地修改数值类型的缓存对象，对象不存在时以zero为原值调用fn并设置过期时间
+// fn返回与原对象类型相同的新值，对象存在时保留原有的过期时间
+func (c *cache) updateNumber(key string, zero interface{}, expiration time.Duration, fn func(v reflect.Value) (reflect.Value, error)) error {
+	return c.UpdateItem(key, func(old *Item) (*Item, error) {
+		if old == nil {
+			v, err := fn(reflect.ValueOf(zero))
+			if err != nil {
+				return nil, err
+			}
+			return &Item{Value: v.Interface(), ExpiredTime: expiredTimeOf(c.now(), expiration)}, nil
+		}
+		v, err := fn(reflect.ValueOf(old.Value))
+		if err != nil {
+			return nil, err
+		}
+		item := old.clone()
+		item.Value = v.Interface()
+		return item, nil
+	})
+}
+
+// overflowError 返回运算结果超出取值范围的错误
+func overflowError(key string, value, delta interface{}, sub bool) error {
+	op := "+"
+	if sub {
+		op = "-"
+	}
+	return fmt.Errorf("%w: %q is %T, %v %s %v", ErrOverflow, key, value, value, op, delta)
+}
+
+// typeError 对象不是数值时返回ErrNotNumeric，是其他数值类型时返回ErrTypeMismatch
+func typeError(key string, value interface{}, want string) error {
+	err := ErrTypeMismatch
+	if !isNumeric(value) {
+		err = ErrNotNumeric
+	}
+	return fmt.Errorf("%w: %q is %T, not %s", err, key, value, want)
+}
+
+func isNumeric(value interface{}) bool {
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
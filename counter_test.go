@@ -0,0 +1,163 @@
+package cache_test
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Nomango/go-cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounter(t *testing.T) {
+	testFunc := func(t *testing.T, c cache.Cache) {
+		// 不存在时创建对象
+		n, err := c.IncrementInt64("int", 2, cache.NoExpiration)
+		assert.Nil(t, err)
+		assert.Equal(t, n, int64(2))
+
+		n, err = c.DecrementInt64("int", 5, cache.NoExpiration)
+		assert.Nil(t, err)
+		assert.Equal(t, n, int64(-3))
+
+		f, err := c.IncrementFloat64("float", 1.5, cache.NoExpiration)
+		assert.Nil(t, err)
+		assert.Equal(t, f, 1.5)
+
+		f, err = c.DecrementFloat64("float", 0.5, cache.NoExpiration)
+		assert.Nil(t, err)
+		assert.Equal(t, f, 1.0)
+
+		// 泛型版本
+		c.Set("uint8", uint8(10))
+		u, err := cache.Increment(c, "uint8", uint8(5), cache.NoExpiration)
+		assert.Nil(t, err)
+		assert.Equal(t, u, uint8(15))
+		u, err = cache.Decrement(c, "uint8", uint8(3), cache.NoExpiration)
+		assert.Nil(t, err)
+		assert.Equal(t, u, uint8(12))
+
+		// 通过Set写入的其他整数类型，保持原有的类型
+		c.Set("n", 5)
+		n, err = c.IncrementInt64("n", 1, cache.NoExpiration)
+		assert.Nil(t, err)
+		assert.Equal(t, n, int64(6))
+		value, _ := c.Get("n")
+		assert.Equal(t, value, 6)
+		n, err = c.DecrementInt64("uint8", 2, cache.NoExpiration)
+		assert.Nil(t, err)
+		assert.Equal(t, n, int64(10))
+		c.Set("float32", float32(1.5))
+		f, err = c.IncrementFloat64("float32", 1, cache.NoExpiration)
+		assert.Nil(t, err)
+		assert.Equal(t, f, 2.5)
+		value, _ = c.Get("float32")
+		assert.Equal(t, value, float32(2.5))
+
+		// 超出类型的取值范围
+		_, err = c.IncrementInt64("uint8", 250, cache.NoExpiration)
+		assert.True(t, errors.Is(err, cache.ErrOverflow))
+		_, err = c.DecrementInt64("uint8", 11, cache.NoExpiration)
+		assert.True(t, errors.Is(err, cache.ErrOverflow))
+		value, _ = c.Get("uint8")
+		assert.Equal(t, value, uint8(10))
+		_, err = cache.Increment(c, "uint8", uint8(250), cache.NoExpiration)
+		assert.True(t, errors.Is(err, cache.ErrOverflow))
+		_, err = cache.Decrement(c, "uint8", uint8(11), cache.NoExpiration)
+		assert.True(t, errors.Is(err, cache.ErrOverflow))
+		value, _ = c.Get("uint8")
+		assert.Equal(t, value, uint8(10))
+		c.Set("int64", int64(5))
+		_, err = c.DecrementInt64("int64", math.MinInt64, cache.NoExpiration)
+		assert.True(t, errors.Is(err, cache.ErrOverflow))
+		_, err = cache.Decrement(c, "int64", int64(math.MinInt64), cache.NoExpiration)
+		assert.True(t, errors.Is(err, cache.ErrOverflow))
+		value, _ = c.Get("int64")
+		assert.Equal(t, value, int64(5))
+		_, err = c.DecrementInt64("uint8", math.MinInt64+1, cache.NoExpiration)
+		assert.True(t, errors.Is(err, cache.ErrOverflow))
+		c.Set("float32", float32(math.MaxFloat32))
+		_, err = cache.Increment(c, "float32", float32(math.MaxFloat32), cache.NoExpiration)
+		assert.True(t, errors.Is(err, cache.ErrOverflow))
+		// 对象不存在时以0减去delta为初始值
+		_, err = cache.Decrement(c, "uint", uint(3), cache.NoExpiration)
+		assert.True(t, errors.Is(err, cache.ErrOverflow))
+		_, found := c.Get("uint")
+		assert.Equal(t, found, false)
+		_, err = c.DecrementInt64("min", math.MinInt64, cache.NoExpiration)
+		assert.True(t, errors.Is(err, cache.ErrOverflow))
+		n, err = c.DecrementInt64("max", math.MaxInt64, cache.NoExpiration)
+		assert.Nil(t, err)
+		assert.Equal(t, n, int64(-math.MaxInt64))
+
+		// 类型不匹配
+		_, err = c.IncrementFloat64("n", 1, cache.NoExpiration)
+		assert.True(t, errors.Is(err, cache.ErrTypeMismatch))
+		_, err = c.IncrementInt64("float", 1, cache.NoExpiration)
+		assert.True(t, errors.Is(err, cache.ErrTypeMismatch))
+		_, err = cache.Increment(c, "n", int64(1), cache.NoExpiration)
+		assert.True(t, errors.Is(err, cache.ErrTypeMismatch))
+		c.Set("str", "test")
+		_, err = c.IncrementInt64("str", 1, cache.NoExpiration)
+		assert.True(t, errors.Is(err, cache.ErrNotNumeric))
+		value, _ = c.Get("str")
+		assert.Equal(t, value, "test")
+	}
+
+	t.Run("Cache", func(t *testing.T) {
+		testFunc(t, cache.New())
+	})
+	t.Run("LRUCache", func(t *testing.T) {
+		testFunc(t, cache.NewWithOptions(&cache.Options{Capacity: 10}))
+	})
+}
+
+func TestCounterExpiration(t *testing.T) {
+	c := cache.New()
+
+	expiration := time.Millisecond * 500
+	_, _ = c.IncrementInt64("key", 1, expiration)
+	item, _ := c.GetItem("key")
+	expiredTime := *item.ExpiredTime
+
+	// 修改对象时保留原有的过期时间
+	time.Sleep(time.Millisecond * 100)
+	_, _ = c.IncrementInt64("key", 1, time.Hour)
+	item, _ = c.GetItem("key")
+	assert.Equal(t, *item.ExpiredTime, expiredTime)
+
+	// 过期后重新创建对象
+	time.Sleep(expiration)
+	n, _ := c.IncrementInt64("key", 1, cache.NoExpiration)
+	assert.Equal(t, n, int64(1))
+	item, _ = c.GetItem("key")
+	assert.Nil(t, item.ExpiredTime)
+}
+
+func TestCounterConcurrent(t *testing.T) {
+	testFunc := func(t *testing.T, c cache.Cache) {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 1000; j++ {
+					_, _ = c.IncrementInt64("key", 1, cache.NoExpiration)
+				}
+			}()
+		}
+		wg.Wait()
+
+		value, _ := c.Get("key")
+		assert.Equal(t, value, int64(10000))
+	}
+
+	t.Run("Cache", func(t *testing.T) {
+		testFunc(t, cache.New())
+	})
+	t.Run("LRUCache", func(t *testing.T) {
+		testFunc(t, cache.NewWithOptions(&cache.Options{Capacity: 10}))
+	})
+}
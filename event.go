@@ -0,0 +1,260 @@
+package cache
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// EventOp 缓存事件类型
+type EventOp int
+
+const (
+	// EventSet 写入缓存对象
+	EventSet EventOp = iota + 1
+	// EventDelete 删除缓存对象
+	EventDelete
+	// EventExpire 缓存对象过期被删除
+	EventExpire
+	// EventEvict 缓存对象因容量限制或内存压力被淘汰
+	EventEvict
+	// EventFlush 清空缓存，Key为空表示清空了整个缓存
+	EventFlush
+	// EventPressure 内存超过软上限，Key总是为空
+	EventPressure
+)
+
+// eventEvictMemory 缓存对象因内存压力被淘汰，只用于内部统计，分发事件时转换为EventEvict
+const eventEvictMemory EventOp = -1
+
+// public 返回分发给订阅者的事件类型
+func (op EventOp) public() EventOp {
+	if op == eventEvictMemory {
+		return EventEvict
+	}
+	return op
+}
+
+func (op EventOp) String() string {
+	switch op {
+	case EventSet:
+		return "set"
+	case EventDelete:
+		return "delete"
+	case EventExpire:
+		return "expire"
+	case EventEvict:
+		return "evict"
+	case EventFlush:
+		return "flush"
+	case EventPressure:
+		return "pressure"
+	}
+	return "unknown"
+}
+
+// Event 缓存事件
+type Event struct {
+	Op  EventOp
+	Key string
+	// OldValue 被覆盖或被删除的值，仅在EventFilter.WithValues为true时设置
+	OldValue interface{}
+	// NewValue 写入的值，仅在EventFilter.WithValues为true时设置
+	NewValue interface{}
+	// Pressure 内存压力信息，仅在EventPressure事件中设置
+	Pressure *MemoryPressure
+}
+
+// OverflowPolicy 订阅者的缓冲区满时的处理策略
+type OverflowPolicy int
+
+const (
+	// DropNewest 丢弃新的事件
+	DropNewest OverflowPolicy = iota
+	// DropOldest 丢弃缓冲区中最旧的事件
+	DropOldest
+)
+
+// EventFilter 事件订阅选项
+// @Ops 订阅的事件类型，为空表示订阅所有类型
+// @KeyPrefix 只订阅key以KeyPrefix开头的事件，Key为空的EventFlush和EventPressure总是会被订阅
+// @WithValues 事件是否携带新旧值
+// @Overflow 缓冲区满时的处理策略，无论使用哪种策略，订阅者都不会阻塞缓存的写操作
+type EventFilter struct {
+	Ops        []EventOp
+	KeyPrefix  string
+	WithValues bool
+	Overflow   OverflowPolicy
+}
+
+func (f *EventFilter) match(op EventOp, key string) bool {
+	if len(f.Ops) > 0 {
+		found := false
+		for _, o := range f.Ops {
+			if o == op {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return (key == "" && (op == EventFlush || op == EventPressure)) || strings.HasPrefix(key, f.KeyPrefix)
+}
+
+// eventHub 事件分发器
+type eventHub struct {
+	mu   sync.RWMutex
+	subs map[*subscriber]struct{}
+	// count 订阅者数量，没有订阅者时跳过事件分发
+	count int32
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{
+		subs: make(map[*subscriber]struct{}),
+	}
+}
+
+// subscriber 订阅者
+type subscriber struct {
+	filter EventFilter
+	// trimPrefix 事件的key需要去掉的前缀，用于命名空间
+	trimPrefix string
+	ch         chan Event
+	dropped    *uint64
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// active 是否有订阅者
+func (h *eventHub) active() bool {
+	return atomic.LoadInt32(&h.count) > 0
+}
+
+func (h *eventHub) subscribe(filter EventFilter, bufferSize int, trimPrefix string, dropped *uint64) (<-chan Event, func()) {
+	if bufferSize <= 0 {
+		bufferSize = 1
+	}
+	filter.KeyPrefix = trimPrefix + filter.KeyPrefix
+	s := &subscriber{
+		filter:     filter,
+		trimPrefix: trimPrefix,
+		ch:         make(chan Event, bufferSize),
+		dropped:    dropped,
+	}
+
+	h.mu.Lock()
+	h.subs[s] = struct{}{}
+	atomic.AddInt32(&h.count, 1)
+	h.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subs, s)
+			atomic.AddInt32(&h.count, -1)
+			h.mu.Unlock()
+			s.close()
+		})
+	}
+	return s.ch, cancel
+}
+
+// publish 分发事件，不会阻塞
+func (h *eventHub) publish(op EventOp, key string, old, val *Item) {
+	if !h.active() {
+		return
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for s := range h.subs {
+		if !s.filter.match(op, key) {
+			continue
+		}
+		e := Event{Op: op, Key: strings.TrimPrefix(key, s.trimPrefix)}
+		if s.filter.WithValues {
+			if old != nil {
+				e.OldValue = old.Value
+			}
+			if val != nil {
+				e.NewValue = val.Value
+			}
+		}
+		s.send(e)
+	}
+}
+
+// publishPressure 分发内存压力事件，不会阻塞
+func (h *eventHub) publishPressure(pressure MemoryPressure) {
+	if !h.active() {
+		return
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for s := range h.subs {
+		if s.filter.match(EventPressure, "") {
+			p := pressure
+			s.send(Event{Op: EventPressure, Pressure: &p})
+		}
+	}
+}
+
+func (s *subscriber) send(e Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+
+	select {
+	case s.ch <- e:
+		return
+	default:
+	}
+
+	// 缓冲区已满
+	atomic.AddUint64(s.dropped, 1)
+	if s.filter.Overflow == DropOldest {
+		select {
+		case <-s.ch:
+		default:
+		}
+		select {
+		case s.ch <- e:
+		default:
+		}
+	}
+}
+
+func (s *subscriber) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	close(s.ch)
+}
+
+// eventSource 可以订阅事件的ItemMap
+type eventSource interface {
+	// eventHub 返回事件分发器，以及事件的key中需要去掉的前缀
+	eventHub() (*eventHub, string)
+}
+
+func (c *cache) Subscribe(filter EventFilter, bufferSize int) (events <-chan Event, cancel func()) {
+	hub, prefix := c.ItemMap.(eventSource).eventHub()
+	return hub.subscribe(filter, bufferSize, prefix, &c.stats.droppedEvents)
+}
+
+// removeReason 删除已过期的缓存项时，原因总是EventExpire
+// 只有删除设置了过期时间的缓存项时才读取时钟
+func removeReason(item *Item, reason EventOp, clock Clock) EventOp {
+	if reason == EventDelete && item.ExpiredTime != nil && item.isExpiredAt(clock.Now()) {
+		return EventExpire
+	}
+	return reason
+}
@@ -0,0 +1,137 @@
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Nomango/go-cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func receiveEvents(events <-chan cache.Event) []cache.Event {
+	var result []cache.Event
+	for {
+		select {
+		case e := <-events:
+			result = append(result, e)
+		default:
+			return result
+		}
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	testFunc := func(t *testing.T, c cache.Cache) {
+		events, cancel := c.Subscribe(cache.EventFilter{WithValues: true}, 100)
+
+		c.Set("key", 1)
+		c.Set("key", 2)
+		c.Delete("key")
+		c.SetWithExpiration("expired", 3, time.Millisecond*100)
+		time.Sleep(time.Millisecond * 200)
+		c.ClearExpired()
+
+		assert.Equal(t, receiveEvents(events), []cache.Event{
+			{Op: cache.EventSet, Key: "key", NewValue: 1},
+			{Op: cache.EventSet, Key: "key", OldValue: 1, NewValue: 2},
+			{Op: cache.EventDelete, Key: "key", OldValue: 2},
+			{Op: cache.EventSet, Key: "expired", NewValue: 3},
+			{Op: cache.EventExpire, Key: "expired", OldValue: 3},
+		})
+
+		// 读取时删除过期对象
+		c.SetWithExpiration("expired", 3, time.Millisecond*100)
+		time.Sleep(time.Millisecond * 200)
+		_, _ = c.Get("expired")
+		result := receiveEvents(events)
+		assert.Equal(t, len(result), 2)
+		assert.Equal(t, result[1].Op, cache.EventExpire)
+
+		// 取消订阅后关闭通道
+		cancel()
+		cancel()
+		c.Set("key", 1)
+		_, ok := <-events
+		assert.Equal(t, ok, false)
+	}
+
+	t.Run("Cache", func(t *testing.T) {
+		testFunc(t, cache.New())
+	})
+	t.Run("LRUCache", func(t *testing.T) {
+		testFunc(t, cache.NewWithOptions(&cache.Options{Capacity: 10}))
+	})
+}
+
+func TestSubscribeFilter(t *testing.T) {
+	c := cache.NewWithOptions(&cache.Options{Capacity: 2})
+
+	deletes, cancel1 := c.Subscribe(cache.EventFilter{Ops: []cache.EventOp{cache.EventDelete, cache.EventEvict}}, 10)
+	defer cancel1()
+	users, cancel2 := c.Subscribe(cache.EventFilter{KeyPrefix: "user:"}, 10)
+	defer cancel2()
+
+	c.Set("user:1", 1)
+	c.Set("order:1", 1)
+	// 淘汰user:1
+	c.Set("order:2", 2)
+	c.Delete("order:1")
+
+	assert.Equal(t, receiveEvents(deletes), []cache.Event{
+		{Op: cache.EventEvict, Key: "user:1"},
+		{Op: cache.EventDelete, Key: "order:1"},
+	})
+	assert.Equal(t, receiveEvents(users), []cache.Event{
+		{Op: cache.EventSet, Key: "user:1"},
+		{Op: cache.EventEvict, Key: "user:1"},
+	})
+
+	// 清空缓存的事件总是会被订阅
+	c.Flush()
+	assert.Equal(t, receiveEvents(users), []cache.Event{
+		{Op: cache.EventFlush},
+	})
+}
+
+func TestSubscribeNamespace(t *testing.T) {
+	c := cache.New()
+	ns := c.Namespace("ns")
+
+	events, cancel := ns.Subscribe(cache.EventFilter{}, 10)
+	defer cancel()
+
+	c.Set("key", 1)
+	ns.Set("key", 2)
+	ns.Flush()
+
+	assert.Equal(t, receiveEvents(events), []cache.Event{
+		{Op: cache.EventSet, Key: "key"},
+		{Op: cache.EventFlush, Key: "key"},
+	})
+}
+
+func TestSubscribeOverflow(t *testing.T) {
+	c := cache.New()
+
+	newest, cancel1 := c.Subscribe(cache.EventFilter{Overflow: cache.DropNewest, WithValues: true}, 2)
+	defer cancel1()
+	oldest, cancel2 := c.Subscribe(cache.EventFilter{Overflow: cache.DropOldest, WithValues: true}, 2)
+	defer cancel2()
+
+	// 缓冲区满时不会阻塞写操作
+	for i := 0; i < 5; i++ {
+		c.Set("key", i)
+	}
+
+	result := receiveEvents(newest)
+	assert.Equal(t, len(result), 2)
+	assert.Equal(t, result[0].NewValue, 0)
+	assert.Equal(t, result[1].NewValue, 1)
+
+	result = receiveEvents(oldest)
+	assert.Equal(t, len(result), 2)
+	assert.Equal(t, result[0].NewValue, 3)
+	assert.Equal(t, result[1].NewValue, 4)
+
+	assert.Equal(t, c.Stats().DroppedEvents, uint64(6))
+}
@@ -0,0 +1,259 @@
+package cache
+
+import (
+	"container/heap"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// sketchDepth count-min sketch的行数
+	sketchDepth = 4
+	// minSketchWidth 每个分片的count-min sketch每行的最小计数器数量
+	minSketchWidth = 256
+	// sketchWidthFactor 每个热点key对应的计数器数量
+	sketchWidthFactor = 64
+	// hotKeyStripeBits 按key的哈希值的高位分片，不同分片的key并发记录时不会竞争同一个锁
+	hotKeyStripeBits = 4
+	hotKeyStripes    = 1 << hotKeyStripeBits
+)
+
+// HotKey 热点key及其估计的访问次数
+type HotKey struct {
+	Key   string
+	Count uint64
+}
+
+// countMinSketch 估计key的访问次数，估计值不会小于实际值
+type countMinSketch struct {
+	mask     uint32
+	counters [sketchDepth][]uint32
+}
+
+func newCountMinSketch(width int) *countMinSketch {
+	// 宽度取2的幂，使用位运算代替取模
+	w := minSketchWidth
+	for w < width {
+		w <<= 1
+	}
+	s := &countMinSketch{mask: uint32(w - 1)}
+	for i := range s.counters {
+		s.counters[i] = make([]uint32, w)
+	}
+	return s
+}
+
+// indexes 计算哈希值为h的key在每一行中的位置，使用两个哈希值组合出多个哈希函数
+func (s *countMinSketch) indexes(h uint32) (idx [sketchDepth]uint32) {
+	h2 := mixHash(h) | 1
+	for i := range idx {
+		idx[i] = (h + uint32(i)*h2) & s.mask
+	}
+	return idx
+}
+
+// add 记录一次哈希值为h的key的访问并返回新的估计值
+// 只增加最小的计数器（conservative update），减少哈希冲突带来的高估
+func (s *countMinSketch) add(h uint32) uint32 {
+	idx := s.indexes(h)
+	min := s.min(idx)
+	if min == ^uint32(0) {
+		return min
+	}
+	for i, j := range idx {
+		if s.counters[i][j] == min {
+			s.counters[i][j]++
+		}
+	}
+	return min + 1
+}
+
+func (s *countMinSketch) min(idx [sketchDepth]uint32) uint32 {
+	min := ^uint32(0)
+	for i, j := range idx {
+		if c := s.counters[i][j]; c < min {
+			min = c
+		}
+	}
+	return min
+}
+
+// decay 所有计数器右移shift位，使估计值更接近最近的访问情况
+func (s *countMinSketch) decay(shift uint) {
+	for i := range s.counters {
+		row := s.counters[i]
+		for j := range row {
+			row[j] >>= shift
+		}
+	}
+}
+
+// mixHash 打散哈希值的比特位，得到与原哈希值近似独立的第二个哈希值
+func mixHash(h uint32) uint32 {
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+	return h
+}
+
+// hotKeyTracker 使用count-min sketch估计访问次数，并用小顶堆保存访问次数最多的k个key
+// 每隔decayInterval将所有计数减半，使排名反映最近的访问情况
+// 按key分成多个分片，每个分片有独立的锁、sketch和堆，只有同一分片的key会竞争锁
+type hotKeyTracker struct {
+	k       int
+	stripes [hotKeyStripes]hotKeyStripe
+}
+
+// hotKeyStripe 热点key统计的一个分片
+type hotKeyStripe struct {
+	mu            sync.Mutex
+	sketch        *countMinSketch
+	top           hotKeyHeap
+	k             int
+	decayInterval time.Duration
+	nextDecay     time.Time
+}
+
+// newHotKeyTracker 未启用热点key统计时返回nil
+func newHotKeyTracker(options *Options, now time.Time) *hotKeyTracker {
+	if options.HotKeyCapacity <= 0 {
+		return nil
+	}
+	interval := options.HotKeyDecayInterval
+	if interval <= 0 {
+		interval = DefaultHotKeyDecayInterval
+	}
+	t := &hotKeyTracker{k: options.HotKeyCapacity}
+	for i := range t.stripes {
+		// 每个分片都保留k个key，合并后的前k个与不分片时相同
+		t.stripes[i] = hotKeyStripe{
+			sketch:        newCountMinSketch(options.HotKeyCapacity * sketchWidthFactor / hotKeyStripes),
+			top:           hotKeyHeap{index: make(map[string]int)},
+			k:             options.HotKeyCapacity,
+			decayInterval: interval,
+			nextDecay:     now.Add(interval),
+		}
+	}
+	return t
+}
+
+// record 记录一次访问，t为nil时不做任何事
+func (t *hotKeyTracker) record(key string, now time.Time) {
+	if t == nil {
+		return
+	}
+	h := hashKey(key)
+	t.stripes[h>>(32-hotKeyStripeBits)].record(key, h, now)
+}
+
+// hotKeys 返回访问次数最多的n个key，按访问次数从高到低排序
+func (t *hotKeyTracker) hotKeys(n int, now time.Time) []HotKey {
+	var keys []HotKey
+	for i := range t.stripes {
+		keys = t.stripes[i].appendTop(keys, now)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Count != keys[j].Count {
+			return keys[i].Count > keys[j].Count
+		}
+		return keys[i].Key < keys[j].Key
+	})
+	if n > t.k {
+		n = t.k
+	}
+	if n < len(keys) {
+		keys = keys[:n]
+	}
+	return keys
+}
+
+// record 记录一次哈希值为h的key的访问
+func (s *hotKeyStripe) record(key string, h uint32, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.decayIfDue(now)
+	count := s.sketch.add(h)
+	if i, ok := s.top.index[key]; ok {
+		s.top.entries[i].Count = uint64(count)
+		heap.Fix(&s.top, i)
+		return
+	}
+	if len(s.top.entries) < s.k {
+		heap.Push(&s.top, HotKey{Key: key, Count: uint64(count)})
+		return
+	}
+	if uint64(count) > s.top.entries[0].Count {
+		// 替换访问次数最少的key
+		delete(s.top.index, s.top.entries[0].Key)
+		s.top.entries[0] = HotKey{Key: key, Count: uint64(count)}
+		s.top.index[key] = 0
+		heap.Fix(&s.top, 0)
+	}
+}
+
+// appendTop 将分片中的热点key追加到keys，没有被访问的分片在读取时衰减
+func (s *hotKeyStripe) appendTop(keys []HotKey, now time.Time) []HotKey {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.decayIfDue(now)
+	return append(keys, s.top.entries...)
+}
+
+// decayIfDue 到达衰减时间时衰减计数，长时间没有访问时按经过的间隔数衰减多次，调用前需持有锁
+func (s *hotKeyStripe) decayIfDue(now time.Time) {
+	if now.Before(s.nextDecay) {
+		return
+	}
+	s.decay(uint(now.Sub(s.nextDecay)/s.decayInterval) + 1)
+	s.nextDecay = now.Add(s.decayInterval)
+}
+
+// decay 所有计数右移shift位，堆中的计数同时衰减，堆的顺序不变
+func (s *hotKeyStripe) decay(shift uint) {
+	if shift > 32 {
+		shift = 32
+	}
+	s.sketch.decay(shift)
+	for i := range s.top.entries {
+		s.top.entries[i].Count >>= shift
+	}
+}
+
+// hotKeyHeap 按访问次数排序的小顶堆，index记录key在堆中的位置
+type hotKeyHeap struct {
+	entries []HotKey
+	index   map[string]int
+}
+
+func (h hotKeyHeap) Len() int           { return len(h.entries) }
+func (h hotKeyHeap) Less(i, j int) bool { return h.entries[i].Count < h.entries[j].Count }
+func (h hotKeyHeap) Swap(i, j int) {
+	h.entries[i], h.entries[j] = h.entries[j], h.entries[i]
+	h.index[h.entries[i].Key] = i
+	h.index[h.entries[j].Key] = j
+}
+func (h *hotKeyHeap) Push(x interface{}) {
+	e := x.(HotKey)
+	h.index[e.Key] = len(h.entries)
+	h.entries = append(h.entries, e)
+}
+func (h *hotKeyHeap) Pop() interface{} {
+	old := h.entries
+	e := old[len(old)-1]
+	h.entries = old[:len(old)-1]
+	delete(h.index, e.Key)
+	return e
+}
+
+func (c *cache) HotKeys(n int) []HotKey {
+	if c.hotKeys == nil || n <= 0 {
+		return nil
+	}
+	return c.hotKeys.hotKeys(n, c.now())
+}
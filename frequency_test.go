@@ -0,0 +1,95 @@
+package cache_test
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Nomango/go-cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHotKeys(t *testing.T) {
+	testFunc := func(t *testing.T, capacity int) {
+		clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+		c, err := cache.NewCache(cache.WithCapacity(capacity), cache.WithClock(clock), cache.WithHotKeys(3, time.Minute))
+		assert.Equal(t, err, nil)
+
+		c.Set("a", 1)
+		c.Set("b", 2)
+		for i := 0; i < 10; i++ {
+			_, _ = c.Get("a")
+		}
+		for i := 0; i < 5; i++ {
+			_, _ = c.Get("missing")
+		}
+		_ = c.GetMulti([]string{"b", "b", "b"})
+		// Peek和Inspect不计入访问次数
+		_, _ = c.Peek("b")
+		_, _ = c.Inspect("b")
+		for i := 0; i < 20; i++ {
+			_, _ = c.Get("key" + strconv.Itoa(i))
+		}
+
+		keys := c.HotKeys(2)
+		assert.Equal(t, keys, []cache.HotKey{{Key: "a", Count: 10}, {Key: "missing", Count: 5}})
+		assert.Equal(t, len(c.HotKeys(10)), 3)
+		assert.Equal(t, c.HotKeys(0), []cache.HotKey(nil))
+
+		// 每过一个衰减间隔访问次数减半
+		clock.Advance(time.Minute)
+		_, _ = c.Get("b")
+		keys = c.HotKeys(3)
+		assert.Equal(t, keys[0], cache.HotKey{Key: "a", Count: 5})
+		assert.Equal(t, keys[1], cache.HotKey{Key: "b", Count: 2})
+
+		// 最近的访问会取代之前的热点key
+		for i := 0; i < 8; i++ {
+			_, _ = c.Get("c")
+		}
+		assert.Equal(t, c.HotKeys(1), []cache.HotKey{{Key: "c", Count: 8}})
+		clock.Advance(time.Minute * 10)
+		_, _ = c.Get("c")
+		assert.Equal(t, c.HotKeys(1), []cache.HotKey{{Key: "c", Count: 1}})
+	}
+	t.Run("Cache", func(t *testing.T) { testFunc(t, 0) })
+	t.Run("LRUCache", func(t *testing.T) { testFunc(t, 10) })
+}
+
+func TestHotKeysDisabled(t *testing.T) {
+	c := cache.New()
+	c.Set("a", 1)
+	_, _ = c.Get("a")
+	assert.Equal(t, c.HotKeys(10), []cache.HotKey(nil))
+}
+
+func TestHotKeysNamespace(t *testing.T) {
+	c := cache.NewWithOptions(&cache.Options{HotKeyCapacity: 10})
+	users := c.Namespace("users")
+	_, _ = users.Get("1")
+	_, _ = c.Get("2")
+
+	// 命名空间拥有独立的热点key统计
+	assert.Equal(t, users.HotKeys(10), []cache.HotKey{{Key: "1", Count: 1}})
+	assert.Equal(t, c.HotKeys(10), []cache.HotKey{{Key: "2", Count: 1}})
+}
+
+func TestHotKeysConcurrent(t *testing.T) {
+	c := cache.NewWithOptions(&cache.Options{HotKeyCapacity: 5})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				_, _ = c.Get("hot")
+				_, _ = c.Get(strconv.Itoa(i*1000 + j))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	keys := c.HotKeys(1)
+	assert.Equal(t, keys, []cache.HotKey{{Key: "hot", Count: 8000}})
+}
@@ -5,22 +5,37 @@ import (
 	"time"
 )
 
+// DefaultName 全局缓存在默认注册表中的名称
+const DefaultName = "default"
+
 type globalCache struct {
 	cache Cache
 	once  sync.Once
 }
 
-func (g *globalCache) lazyInit(options *Options) {
+// lazyInit 初始化全局缓存，已经初始化时返回ErrAlreadyInitialized
+// 已经通过Register注册了DefaultName时，使用已注册的缓存并返回ErrAlreadyRegistered
+func (g *globalCache) lazyInit(options *Options) error {
+	err := ErrAlreadyInitialized
 	g.once.Do(func() {
-		g.cache = NewWithOptions(options)
+		g.cache, err = DefaultRegistry.register(DefaultName, func() Cache {
+			return NewWithOptions(options)
+		})
 	})
+	return err
 }
 
 var global globalCache
 
-// Init 初始化全局缓存
-func Init(options *Options) {
-	global.lazyInit(options)
+// Init 初始化全局缓存，全局缓存会以DefaultName注册到默认注册表中
+// 选项不合法时返回错误，全局缓存已经初始化(包括使用全局函数时的自动初始化)时返回ErrAlreadyInitialized
+func Init(options *Options) error {
+	if options != nil {
+		if err := options.Validate(); err != nil {
+			return err
+		}
+	}
+	return global.lazyInit(options)
 }
 
 // Set 缓存一个对象
@@ -47,6 +62,24 @@ func Delete(key string) {
 	global.cache.Delete(key)
 }
 
+// GetMulti 批量获取缓存对象
+func GetMulti(keys []string) map[string]interface{} {
+	global.lazyInit(nil)
+	return global.cache.GetMulti(keys)
+}
+
+// SetMulti 批量缓存对象，并设置过期时间
+func SetMulti(items map[string]interface{}, expiration time.Duration) {
+	global.lazyInit(nil)
+	global.cache.SetMulti(items, expiration)
+}
+
+// DeleteMulti 批量删除缓存对象
+func DeleteMulti(keys []string) {
+	global.lazyInit(nil)
+	global.cache.DeleteMulti(keys)
+}
+
 // Global 获取全局缓存
 func Global() Cache {
 	global.lazyInit(nil)
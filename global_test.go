@@ -1,6 +1,7 @@
 package cache_test
 
 import (
+	"errors"
 	"math/rand"
 	"testing"
 	"time"
@@ -58,3 +59,44 @@ func TestGlobalCache(t *testing.T) {
 	assert.Equal(t, found, true)
 	assert.Equal(t, value, num1)
 }
+
+func TestGlobalCacheMulti(t *testing.T) {
+	cache.SetMulti(map[string]interface{}{
+		"multi1": 1,
+		"multi2": 2,
+	}, cache.NoExpiration)
+
+	values := cache.GetMulti([]string{"multi1", "multi2", "multi3"})
+	assert.Equal(t, values, map[string]interface{}{"multi1": 1, "multi2": 2})
+
+	cache.DeleteMulti([]string{"multi1", "multi2"})
+	values = cache.GetMulti([]string{"multi1", "multi2"})
+	assert.Equal(t, len(values), 0)
+}
+
+func TestGlobalRegistry(t *testing.T) {
+	global := cache.Global()
+
+	// 全局缓存已经初始化
+	assert.Equal(t, cache.Init(nil), cache.ErrAlreadyInitialized)
+
+	// 全局缓存注册在默认注册表中
+	c, ok := cache.Lookup(cache.DefaultName)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, c, global)
+	assert.Equal(t, cache.MustGet(cache.DefaultName), global)
+}
+
+func TestGlobalCloseAll(t *testing.T) {
+	global := cache.Global()
+	assert.Equal(t, cache.Register("closeall", nil), nil)
+
+	// 全局缓存关闭后仍然注册在默认注册表中，与Global()一致
+	cache.CloseAll()
+	assert.Equal(t, cache.Names(), []string{cache.DefaultName})
+	c, ok := cache.Lookup(cache.DefaultName)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, c, global)
+	assert.Equal(t, cache.Global(), global)
+	assert.True(t, errors.Is(cache.Register(cache.DefaultName, nil), cache.ErrAlreadyRegistered))
+}
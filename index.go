@@ -0,0 +1,32 @@
+package cache
+
+// itemIndex 缓存项的辅助索引，缓存项添加或删除时同步更新
+// 调用时持有缓存项所在key的锁，索引需要自行保证并发安全
+type itemIndex interface {
+	// onAdd 添加缓存项，old为被覆盖的缓存项，不存在时为nil
+	onAdd(key string, old, val *Item)
+	// onRemove 删除缓存项，reason为删除的原因
+	onRemove(key string, item *Item, reason EventOp)
+	// onFlush 清空缓存
+	onFlush()
+}
+
+type itemIndexes []itemIndex
+
+func (indexes itemIndexes) add(key string, old, val *Item) {
+	for _, index := range indexes {
+		index.onAdd(key, old, val)
+	}
+}
+
+func (indexes itemIndexes) remove(key string, item *Item, reason EventOp) {
+	for _, index := range indexes {
+		index.onRemove(key, item, reason)
+	}
+}
+
+func (indexes itemIndexes) flush() {
+	for _, index := range indexes {
+		index.onFlush()
+	}
+}
@@ -0,0 +1,363 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// GetFunc 获取缓存对象的操作
+type GetFunc func(key string) (value interface{}, found bool)
+
+// SetFunc 缓存对象并设置过期时间的操作，Set使用当前的默认过期时长
+type SetFunc func(key string, val interface{}, expiration time.Duration)
+
+// DeleteFunc 删除缓存对象的操作
+type DeleteFunc func(key string)
+
+// RangeFunc 遍历缓存对象的操作
+type RangeFunc func(fn func(key string, value interface{}) bool)
+
+// UpdateFunc 原子地读取并修改缓存对象的操作
+type UpdateFunc func(key string)
+
+// Interceptor 拦截器，在缓存操作前后执行日志、指标、key校验、租户前缀等通用逻辑
+// 每个字段拦截一类操作，调用next执行后续的拦截器和实际的操作，可以修改参数和返回值，不调用next则跳过该操作
+// 字段为nil时不拦截该类操作，批量操作对每个key分别执行拦截器，没有拦截器拦截对应操作时整批执行
+// @Get 拦截Get、GetCtx、GetWithVersion、GetMulti，以及GetOrLoadCtx读取缓存对象
+// @Set 拦截Set、SetWithExpiration、SetWithTags、SetWithOptions、SetUntil、SetIfVersion、SetMulti、SetCtx、SetWithExpirationCtx，
+// 以及GetOrLoadCtx缓存加载结果，next的expiration为实际使用的过期时长
+// @Delete 拦截Delete、DeleteCtx、DeleteMulti
+// @Range 拦截Range和RangeItems
+// @Update 拦截Update、Compute、ComputeIfAbsent、ComputeIfPresent、Increment*、Decrement*和UpdateItem，跳过时返回零值
+//
+// 其他方法不经过拦截器，直接操作缓存，包括Peek、Contains、Inspect、TTL、Expire、ExpireAt、Persist、Touch、
+// RangePrefix、RangeBetween、Scan、InvalidateTag、DeletePrefix、Subscribe，以及UpdateItem以外的ItemMap方法
+type Interceptor struct {
+	Get    func(key string, next GetFunc) (value interface{}, found bool)
+	Set    func(key string, val interface{}, expiration time.Duration, next SetFunc)
+	Delete func(key string, next DeleteFunc)
+	Range  func(fn func(key string, value interface{}) bool, next RangeFunc)
+	Update func(key string, next UpdateFunc)
+}
+
+// interceptedCache 按顺序执行拦截器的缓存，第一个拦截器在最外层
+type interceptedCache struct {
+	*cache
+	interceptors []Interceptor
+
+	get GetFunc
+	set SetFunc
+	del DeleteFunc
+	rng RangeFunc
+
+	// 没有拦截器拦截对应的操作时，批量操作直接调用cache的批量方法，整批只加一次锁
+	hasGet    bool
+	hasSet    bool
+	hasDelete bool
+}
+
+// newInterceptedCache 没有拦截器时直接返回c
+func newInterceptedCache(c *cache, interceptors []Interceptor) Cache {
+	if len(interceptors) == 0 {
+		return c
+	}
+
+	ic := &interceptedCache{
+		cache:        c,
+		interceptors: interceptors,
+	}
+	// 常用的操作预先组装拦截器链，避免每次调用时分配内存
+	ic.get = ic.getChain(c.Get)
+	ic.set = ic.setChain(c.SetWithExpiration)
+	ic.del = ic.deleteChain(c.Delete)
+	ic.rng = ic.rangeChain(c.Range)
+	for _, in := range interceptors {
+		ic.hasGet = ic.hasGet || in.Get != nil
+		ic.hasSet = ic.hasSet || in.Set != nil
+		ic.hasDelete = ic.hasDelete || in.Delete != nil
+	}
+	return ic
+}
+
+// getChain 组装Get拦截器链，op为实际的操作
+func (c *interceptedCache) getChain(op GetFunc) GetFunc {
+	for i := len(c.interceptors) - 1; i >= 0; i-- {
+		if in := c.interceptors[i].Get; in != nil {
+			next := op
+			op = func(key string) (interface{}, bool) {
+				return in(key, next)
+			}
+		}
+	}
+	return op
+}
+
+// setChain 组装Set拦截器链，op为实际的操作
+func (c *interceptedCache) setChain(op SetFunc) SetFunc {
+	for i := len(c.interceptors) - 1; i >= 0; i-- {
+		if in := c.interceptors[i].Set; in != nil {
+			next := op
+			op = func(key string, val interface{}, expiration time.Duration) {
+				in(key, val, expiration, next)
+			}
+		}
+	}
+	return op
+}
+
+// deleteChain 组装Delete拦截器链，op为实际的操作
+func (c *interceptedCache) deleteChain(op DeleteFunc) DeleteFunc {
+	for i := len(c.interceptors) - 1; i >= 0; i-- {
+		if in := c.interceptors[i].Delete; in != nil {
+			next := op
+			op = func(key string) {
+				in(key, next)
+			}
+		}
+	}
+	return op
+}
+
+// rangeChain 组装Range拦截器链，op为实际的操作
+func (c *interceptedCache) rangeChain(op RangeFunc) RangeFunc {
+	for i := len(c.interceptors) - 1; i >= 0; i-- {
+		if in := c.interceptors[i].Range; in != nil {
+			next := op
+			op = func(fn func(key string, value interface{}) bool) {
+				in(fn, next)
+			}
+		}
+	}
+	return op
+}
+
+// update 依次执行Update拦截器，最后执行op
+func (c *interceptedCache) update(key string, op UpdateFunc) {
+	for i := len(c.interceptors) - 1; i >= 0; i-- {
+		if in := c.interceptors[i].Update; in != nil {
+			next := op
+			op = func(key string) {
+				in(key, next)
+			}
+		}
+	}
+	op(key)
+}
+
+func (c *interceptedCache) Get(key string) (value interface{}, found bool) {
+	return c.get(key)
+}
+
+func (c *interceptedCache) GetWithVersion(key string) (value interface{}, version uint64, found bool) {
+	value, found = c.getChain(func(key string) (value interface{}, found bool) {
+		value, version, found = c.cache.GetWithVersion(key)
+		return value, found
+	})(key)
+	return value, version, found
+}
+
+func (c *interceptedCache) GetMulti(keys []string) map[string]interface{} {
+	if !c.hasGet {
+		return c.cache.GetMulti(keys)
+	}
+	values := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		if value, found := c.get(key); found {
+			values[key] = value
+		}
+	}
+	return values
+}
+
+func (c *interceptedCache) Set(key string, val interface{}) {
+	c.set(key, val, c.defaultExpiration())
+}
+
+func (c *interceptedCache) SetWithExpiration(key string, val interface{}, expiration time.Duration) {
+	c.set(key, val, expiration)
+}
+
+func (c *interceptedCache) SetWithTags(key string, val interface{}, expiration time.Duration, tags ...string) {
+	c.setChain(func(key string, val interface{}, expiration time.Duration) {
+		c.cache.SetWithTags(key, val, expiration, tags...)
+	})(key, val, expiration)
+}
+
+func (c *interceptedCache) SetWithOptions(key string, val interface{}, opts ItemOptions) {
+	c.setChain(func(key string, val interface{}, expiration time.Duration) {
+		opts.TTL = expiration
+		c.cache.SetWithOptions(key, val, opts)
+	})(key, val, opts.TTL)
+}
+
+func (c *interceptedCache) SetUntil(key string, val interface{}, deadline time.Time) {
+	until := deadline.Sub(c.now())
+	c.setChain(func(key string, val interface{}, expiration time.Duration) {
+		if expiration != until {
+			// 拦截器修改了过期时长
+			c.cache.SetWithExpiration(key, val, expiration)
+			return
+		}
+		c.cache.SetUntil(key, val, deadline)
+	})(key, val, until)
+}
+
+func (c *interceptedCache) SetIfVersion(key string, val interface{}, version uint64, expiration time.Duration) (ok bool) {
+	c.setChain(func(key string, val interface{}, expiration time.Duration) {
+		ok = c.cache.SetIfVersion(key, val, version, expiration)
+	})(key, val, expiration)
+	return ok
+}
+
+func (c *interceptedCache) SetMulti(items map[string]interface{}, expiration time.Duration) {
+	if !c.hasSet {
+		c.cache.SetMulti(items, expiration)
+		return
+	}
+	for key, val := range items {
+		c.set(key, val, expiration)
+	}
+}
+
+func (c *interceptedCache) Delete(key string) {
+	c.del(key)
+}
+
+func (c *interceptedCache) DeleteMulti(keys []string) {
+	if !c.hasDelete {
+		c.cache.DeleteMulti(keys)
+		return
+	}
+	for _, key := range keys {
+		c.del(key)
+	}
+}
+
+func (c *interceptedCache) Range(fn func(key string, value interface{}) bool) {
+	c.rng(fn)
+}
+
+func (c *interceptedCache) RangeItems(op func(string, ItemInfo) bool) {
+	if op == nil {
+		return
+	}
+	// 拦截器可以修改key和value，元信息的其他字段来自当前遍历的对象
+	var current ItemInfo
+	c.rangeChain(func(fn func(key string, value interface{}) bool) {
+		c.cache.RangeItems(func(key string, info ItemInfo) bool {
+			current = info
+			return fn(key, info.Value)
+		})
+	})(func(key string, value interface{}) bool {
+		info := current
+		info.Value = value
+		return op(key, info)
+	})
+}
+
+func (c *interceptedCache) Update(key string, fn func(old interface{}, exists bool) (new interface{}, keep bool)) (value interface{}, found bool) {
+	c.update(key, func(key string) {
+		value, found = c.cache.Update(key, fn)
+	})
+	return value, found
+}
+
+func (c *interceptedCache) Compute(key string, fn func(old interface{}, exists bool) (new interface{}, keep bool), expiration time.Duration) (value interface{}, found bool) {
+	c.update(key, func(key string) {
+		value, found = c.cache.Compute(key, fn, expiration)
+	})
+	return value, found
+}
+
+func (c *interceptedCache) ComputeIfAbsent(key string, fn func() (new interface{}, keep bool), expiration time.Duration) (value interface{}, found bool) {
+	c.update(key, func(key string) {
+		value, found = c.cache.ComputeIfAbsent(key, fn, expiration)
+	})
+	return value, found
+}
+
+func (c *interceptedCache) ComputeIfPresent(key string, fn func(old interface{}) (new interface{}, keep bool)) (value interface{}, found bool) {
+	c.update(key, func(key string) {
+		value, found = c.cache.ComputeIfPresent(key, fn)
+	})
+	return value, found
+}
+
+func (c *interceptedCache) IncrementInt64(key string, delta int64, expiration time.Duration) (n int64, err error) {
+	c.update(key, func(key string) {
+		n, err = c.cache.IncrementInt64(key, delta, expiration)
+	})
+	return n, err
+}
+
+func (c *interceptedCache) IncrementFloat64(key string, delta float64, expiration time.Duration) (n float64, err error) {
+	c.update(key, func(key string) {
+		n, err = c.cache.IncrementFloat64(key, delta, expiration)
+	})
+	return n, err
+}
+
+func (c *interceptedCache) DecrementInt64(key string, delta int64, expiration time.Duration) (n int64, err error) {
+	c.update(key, func(key string) {
+		n, err = c.cache.DecrementInt64(key, delta, expiration)
+	})
+	return n, err
+}
+
+func (c *interceptedCache) DecrementFloat64(key string, delta float64, expiration time.Duration) (n float64, err error) {
+	c.update(key, func(key string) {
+		n, err = c.cache.DecrementFloat64(key, delta, expiration)
+	})
+	return n, err
+}
+
+// UpdateItem 泛型函数Increment和Decrement通过UpdateItem修改缓存对象
+func (c *interceptedCache) UpdateItem(key string, fn func(old *Item) (*Item, error)) (err error) {
+	c.update(key, func(key string) {
+		err = c.cache.UpdateItem(key, fn)
+	})
+	return err
+}
+
+func (c *interceptedCache) GetCtx(ctx context.Context, key string) (value interface{}, found bool, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+	value, found = c.get(key)
+	return value, found, nil
+}
+
+func (c *interceptedCache) SetCtx(ctx context.Context, key string, val interface{}) error {
+	return c.SetWithExpirationCtx(ctx, key, val, c.defaultExpiration())
+}
+
+func (c *interceptedCache) SetWithExpirationCtx(ctx context.Context, key string, val interface{}, expiration time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.set(key, val, expiration)
+	return nil
+}
+
+func (c *interceptedCache) DeleteCtx(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.del(key)
+	return nil
+}
+
+func (c *interceptedCache) GetOrLoadCtx(ctx context.Context, key string, loader LoaderFunc, expiration time.Duration) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if value, found := c.get(key); found {
+		return value, nil
+	}
+	return c.loadAndSet(ctx, key, loader, expiration, c.set)
+}
+
+// Namespace 命名空间使用相同的拦截器，拦截器收到的是命名空间中的key
+func (c *interceptedCache) Namespace(name string) Cache {
+	return newInterceptedCache(c.cache.Namespace(name).(*cache), c.interceptors)
+}
@@ -0,0 +1,259 @@
+package cache_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Nomango/go-cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// tenantInterceptor 为所有key加上租户前缀
+func tenantInterceptor(tenant string) cache.Interceptor {
+	prefix := tenant + ":"
+	return cache.Interceptor{
+		Get: func(key string, next cache.GetFunc) (interface{}, bool) {
+			return next(prefix + key)
+		},
+		Set: func(key string, val interface{}, expiration time.Duration, next cache.SetFunc) {
+			next(prefix+key, val, expiration)
+		},
+		Delete: func(key string, next cache.DeleteFunc) {
+			next(prefix + key)
+		},
+		Range: func(fn func(key string, value interface{}) bool, next cache.RangeFunc) {
+			next(func(key string, value interface{}) bool {
+				if !strings.HasPrefix(key, prefix) {
+					return true
+				}
+				return fn(strings.TrimPrefix(key, prefix), value)
+			})
+		},
+		Update: func(key string, next cache.UpdateFunc) {
+			next(prefix + key)
+		},
+	}
+}
+
+func TestInterceptors(t *testing.T) {
+	testFunc := func(t *testing.T, capacity int) {
+		var calls []string
+		logger := cache.Interceptor{
+			Get: func(key string, next cache.GetFunc) (interface{}, bool) {
+				calls = append(calls, "get "+key)
+				return next(key)
+			},
+			Set: func(key string, val interface{}, expiration time.Duration, next cache.SetFunc) {
+				calls = append(calls, "set "+key)
+				next(key, val, expiration)
+			},
+		}
+		c, err := cache.NewCache(
+			cache.WithCapacity(capacity),
+			cache.WithDefaultExpiration(time.Minute),
+			cache.WithInterceptors(logger, tenantInterceptor("t1")),
+		)
+		assert.Equal(t, err, nil)
+
+		c.Set("key", 1)
+		v, ok := c.Get("key")
+		assert.Equal(t, ok, true)
+		assert.Equal(t, v, 1)
+		// 第一个拦截器在最外层，收到的是未加前缀的key
+		assert.Equal(t, calls, []string{"set key", "get key"})
+		// Peek不经过拦截器
+		assert.Equal(t, c.Contains("key"), false)
+		assert.Equal(t, c.Contains("t1:key"), true)
+		// Set使用默认过期时长
+		ttl, _ := c.TTL("t1:key")
+		assert.True(t, ttl > 0 && ttl <= time.Minute)
+
+		c.SetWithExpiration("other", 2, time.Second)
+		ttl, _ = c.TTL("t1:other")
+		assert.True(t, ttl > 0 && ttl <= time.Second)
+		c.SetWithExpiration("t2:key", 3, cache.NoExpiration)
+
+		keys := map[string]interface{}{}
+		c.Range(func(key string, value interface{}) bool {
+			keys[key] = value
+			return true
+		})
+		assert.Equal(t, keys, map[string]interface{}{"key": 1, "other": 2, "t2:key": 3})
+
+		c.Delete("key")
+		assert.Equal(t, c.Contains("t1:key"), false)
+	}
+	t.Run("Cache", func(t *testing.T) { testFunc(t, 0) })
+	t.Run("LRUCache", func(t *testing.T) { testFunc(t, 10) })
+}
+
+func TestInterceptorSkip(t *testing.T) {
+	// 校验key，不合法时不调用next
+	validator := cache.Interceptor{
+		Get: func(key string, next cache.GetFunc) (interface{}, bool) {
+			if key == "" {
+				return nil, false
+			}
+			return next(key)
+		},
+		Set: func(key string, val interface{}, expiration time.Duration, next cache.SetFunc) {
+			if key != "" {
+				next(key, val, expiration)
+			}
+		},
+	}
+	c := cache.NewWithOptions(&cache.Options{Interceptors: []cache.Interceptor{validator}})
+	c.Set("", 1)
+	assert.Equal(t, c.Len(), 0)
+	_, _ = c.Get("")
+	assert.Equal(t, c.Stats().Misses, uint64(0))
+
+	// 未设置的操作不拦截
+	c.Set("key", 1)
+	c.Delete("key")
+	assert.Equal(t, c.Len(), 0)
+}
+
+func TestInterceptorsCtx(t *testing.T) {
+	c, _ := cache.NewCache(cache.WithInterceptors(tenantInterceptor("t1")))
+	ctx := context.Background()
+
+	assert.Equal(t, c.SetCtx(ctx, "a", 1), nil)
+	assert.Equal(t, c.SetWithExpirationCtx(ctx, "b", 2, time.Minute), nil)
+	v, ok, err := c.GetCtx(ctx, "a")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, v, 1)
+	assert.Equal(t, c.Contains("t1:b"), true)
+	assert.Equal(t, c.DeleteCtx(ctx, "a"), nil)
+	assert.Equal(t, c.Contains("t1:a"), false)
+
+	// ctx已取消时不调用拦截器
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.Equal(t, c.SetCtx(canceled, "c", 3), context.Canceled)
+	assert.Equal(t, c.Contains("t1:c"), false)
+}
+
+func TestInterceptorsAllOperations(t *testing.T) {
+	testFunc := func(t *testing.T, capacity int) {
+		c, _ := cache.NewCache(cache.WithCapacity(capacity), cache.WithInterceptors(tenantInterceptor("t1")))
+		ctx := context.Background()
+
+		// GetOrLoadCtx读取和缓存加载结果都经过拦截器
+		v, err := c.GetOrLoadCtx(ctx, "load", func(ctx context.Context) (interface{}, error) {
+			return "loaded", nil
+		}, time.Minute)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, v, "loaded")
+		assert.Equal(t, c.Contains("t1:load"), true)
+		assert.Equal(t, c.Contains("load"), false)
+		c.SetWithExpiration("hit", "tenant", cache.NoExpiration)
+		v, err = c.GetOrLoadCtx(ctx, "hit", func(ctx context.Context) (interface{}, error) {
+			t.Fatal("should not load")
+			return nil, nil
+		}, time.Minute)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, v, "tenant")
+
+		// 批量操作
+		c.SetMulti(map[string]interface{}{"a": 1, "b": 2}, cache.NoExpiration)
+		assert.Equal(t, c.Contains("t1:a"), true)
+		assert.Equal(t, c.GetMulti([]string{"a", "b", "c", "load"}), map[string]interface{}{"a": 1, "b": 2, "load": "loaded"})
+		c.DeleteMulti([]string{"a", "b"})
+		assert.Equal(t, c.Contains("t1:a"), false)
+		assert.Equal(t, c.Contains("t1:b"), false)
+
+		// Set的各种形式
+		c.SetWithTags("tagged", 1, cache.NoExpiration, "tag")
+		c.SetWithOptions("options", 2, cache.ItemOptions{TTL: time.Minute, Priority: 1})
+		c.SetUntil("until", 3, time.Now().Add(time.Minute))
+		info, _ := c.Inspect("t1:options")
+		assert.Equal(t, info.Priority, 1)
+		ttl, _ := c.TTL("t1:until")
+		assert.True(t, ttl > 0 && ttl <= time.Minute)
+		assert.Equal(t, c.InvalidateTag("tag"), 1)
+		assert.Equal(t, c.Contains("t1:tagged"), false)
+
+		_, version, found := c.GetWithVersion("options")
+		assert.Equal(t, found, true)
+		assert.Equal(t, c.SetIfVersion("options", 4, version, cache.NoExpiration), true)
+		v, _ = c.Peek("t1:options")
+		assert.Equal(t, v, 4)
+
+		// 读改写操作
+		v, _ = c.Update("counter", func(old interface{}, exists bool) (interface{}, bool) {
+			return 1, true
+		})
+		assert.Equal(t, v, 1)
+		n, err := c.IncrementInt64("counter", 2, cache.NoExpiration)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, n, int64(3))
+		i, _ := cache.Increment(c, "counter", 1, cache.NoExpiration)
+		assert.Equal(t, i, 4)
+		v, _ = c.ComputeIfPresent("counter", func(old interface{}) (interface{}, bool) {
+			return old.(int) * 10, true
+		})
+		assert.Equal(t, v, 40)
+		v, _ = c.Peek("t1:counter")
+		assert.Equal(t, v, 40)
+		v, _ = c.ComputeIfAbsent("absent", func() (interface{}, bool) { return 5, true }, cache.NoExpiration)
+		assert.Equal(t, v, 5)
+		assert.Equal(t, c.Contains("t1:absent"), true)
+
+		// RangeItems经过Range拦截器
+		keys := map[string]interface{}{}
+		c.RangeItems(func(key string, info cache.ItemInfo) bool {
+			keys[key] = info.Value
+			return true
+		})
+		assert.Equal(t, keys, map[string]interface{}{
+			"load": "loaded", "hit": "tenant", "options": 4, "until": 3, "counter": 40, "absent": 5,
+		})
+	}
+	t.Run("Cache", func(t *testing.T) { testFunc(t, 0) })
+	t.Run("LRUCache", func(t *testing.T) { testFunc(t, 20) })
+}
+
+func TestInterceptorsBatchWithoutChain(t *testing.T) {
+	// 只有Range拦截器时，批量操作直接调用缓存的批量方法
+	var ranges int
+	c := cache.NewWithOptions(&cache.Options{
+		Capacity: 10,
+		Interceptors: []cache.Interceptor{{
+			Range: func(fn func(key string, value interface{}) bool, next cache.RangeFunc) {
+				ranges++
+				next(fn)
+			},
+		}},
+	})
+	c.SetMulti(map[string]interface{}{"a": 1, "b": 2}, cache.NoExpiration)
+	assert.Equal(t, c.GetMulti([]string{"a", "b", "c"}), map[string]interface{}{"a": 1, "b": 2})
+	c.DeleteMulti([]string{"a"})
+	assert.Equal(t, c.Contains("a"), false)
+	assert.Equal(t, c.Stats().Hits, uint64(2))
+	assert.Equal(t, c.Stats().Misses, uint64(1))
+
+	c.Range(func(key string, value interface{}) bool { return true })
+	assert.Equal(t, ranges, 1)
+}
+
+func TestInterceptorsNamespace(t *testing.T) {
+	var keys []string
+	c := cache.NewWithOptions(&cache.Options{Interceptors: []cache.Interceptor{{
+		Set: func(key string, val interface{}, expiration time.Duration, next cache.SetFunc) {
+			keys = append(keys, key)
+			next(key, val, expiration)
+		},
+	}}})
+
+	// 命名空间使用相同的拦截器，拦截器收到的是命名空间中的key
+	users := c.Namespace("users")
+	users.Set("1", 1)
+	users.Namespace("admin").Set("2", 2)
+	assert.Equal(t, keys, []string{"1", "2"})
+	v, _ := users.Get("1")
+	assert.Equal(t, v, 1)
+}
@@ -1,6 +1,8 @@
 package cache
 
 import (
+	"container/heap"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -8,38 +10,205 @@ import (
 
 // Item 缓存项
 type Item struct {
+	// hits和accessedTime会被并发修改，需要原子操作，放在结构体开头以保证64位对齐
+	hits         uint64
+	accessedTime int64
+
 	Value       interface{}
 	ExpiredTime *time.Time
+	// Version 版本号，缓存项写入时分配，单调递增
+	Version uint64
+	// Tags 标签，可以通过标签批量删除缓存项
+	Tags []string
+	// CreatedTime 创建时间，缓存项第一次写入时设置，修改缓存项时保留
+	CreatedTime time.Time
+	// Cost 缓存项的成本，由调用方设置
+	Cost int64
+	// Priority 优先级，LRU缓存超过容量时优先淘汰低优先级的缓存项
+	Priority int
+	// Pinned 固定的缓存项不会因LRU缓存超过容量而被淘汰
+	Pinned bool
+}
+
+// ItemOptions 缓存项的选项
+// @TTL 过期时长，为NoExpiration时永不过期
+// @Priority 优先级，LRU缓存超过容量时优先淘汰低优先级的缓存项
+// @Pinned 固定的缓存项不占用LRU缓存的容量，不会因超过容量而被淘汰
+// @Tags 标签
+// @Cost 缓存项的成本
+type ItemOptions struct {
+	TTL      time.Duration
+	Priority int
+	Pinned   bool
+	Tags     []string
+	Cost     int64
 }
 
 func NewItem(val interface{}, expiration time.Duration) *Item {
-	if expiration == NoExpiration {
-		return &Item{val, nil}
+	return &Item{
+		Value:       val,
+		ExpiredTime: expiredTimeOf(time.Now(), expiration),
 	}
-	expiredTime := time.Now().Add(expiration)
+}
+
+// NewItemWithDeadline 新建一个在deadline时间点过期的缓存项
+func NewItemWithDeadline(val interface{}, deadline time.Time) *Item {
 	return &Item{
 		Value:       val,
-		ExpiredTime: &expiredTime,
+		ExpiredTime: &deadline,
+	}
+}
+
+// expiredTimeOf 根据过期时长计算从now开始的过期时间，永不过期时返回nil
+func expiredTimeOf(now time.Time, expiration time.Duration) *time.Time {
+	if expiration == NoExpiration {
+		return nil
 	}
+	expiredTime := now.Add(expiration)
+	return &expiredTime
 }
 
 // IsExpired 对象是否过期
 func (i *Item) IsExpired() bool {
+	return i.isExpiredAt(time.Now())
+}
+
+// isExpiredAt 对象在now时间点是否过期
+func (i *Item) isExpiredAt(now time.Time) bool {
 	if i.ExpiredTime == nil {
 		// 永不过期的对象
 		return false
 	}
-	return time.Now().After(*i.ExpiredTime)
+	return now.After(*i.ExpiredTime)
+}
+
+// HasTag 缓存项是否包含标签
+func (i *Item) HasTag(tag string) bool {
+	for _, t := range i.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Hits 缓存项被访问的次数
+func (i *Item) Hits() uint64 {
+	return atomic.LoadUint64(&i.hits)
+}
+
+// accessedTimeResolution 访问时间的精度
+const accessedTimeResolution = time.Millisecond
+
+// AccessedTime 缓存项最后一次被访问的时间，精确到毫秒，未被访问过时为零值
+func (i *Item) AccessedTime() time.Time {
+	accessedTime := atomic.LoadInt64(&i.accessedTime)
+	if accessedTime == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, accessedTime)
+}
+
+// lastAccessed 最后一次被访问的时间，未被访问过时为创建时间
+func (i *Item) lastAccessed() time.Time {
+	if accessedTime := i.AccessedTime(); !accessedTime.IsZero() {
+		return accessedTime
+	}
+	return i.CreatedTime
+}
+
+// touch 记录一次在now时间点的访问
+// 访问时间精确到毫秒，同一毫秒内的多次访问只写入一次，减少并发读取同一对象时的写竞争
+func (i *Item) touch(now time.Time) {
+	atomic.AddUint64(&i.hits, 1)
+	accessedTime := now.UnixNano()
+	if accessedTime-atomic.LoadInt64(&i.accessedTime) >= int64(accessedTimeResolution) {
+		atomic.StoreInt64(&i.accessedTime, accessedTime)
+	}
+}
+
+// clone 复制缓存项，用于修改缓存项时保留原有的属性
+func (i *Item) clone() *Item {
+	return &Item{
+		hits:         atomic.LoadUint64(&i.hits),
+		accessedTime: atomic.LoadInt64(&i.accessedTime),
+		Value:        i.Value,
+		ExpiredTime:  i.ExpiredTime,
+		Version:      i.Version,
+		Tags:         i.Tags,
+		CreatedTime:  i.CreatedTime,
+		Cost:         i.Cost,
+		Priority:     i.Priority,
+		Pinned:       i.Pinned,
+	}
+}
+
+func (i *Item) info() ItemInfo {
+	return ItemInfo{
+		Value:        i.Value,
+		ExpiredTime:  i.ExpiredTime,
+		Version:      i.Version,
+		Tags:         i.Tags,
+		CreatedTime:  i.CreatedTime,
+		AccessedTime: i.AccessedTime(),
+		Hits:         i.Hits(),
+		Cost:         i.Cost,
+		Priority:     i.Priority,
+		Pinned:       i.Pinned,
+	}
+}
+
+// ItemInfo 缓存项的元信息
+type ItemInfo struct {
+	Value        interface{}
+	ExpiredTime  *time.Time
+	Version      uint64
+	Tags         []string
+	CreatedTime  time.Time
+	AccessedTime time.Time
+	Hits         uint64
+	Cost         int64
+	Priority     int
+	Pinned       bool
+}
+
+// versionCounter 全局版本号计数器
+var versionCounter uint64
+
+func nextVersion() uint64 {
+	return atomic.AddUint64(&versionCounter, 1)
 }
 
 // ItemMap
 type ItemMap interface {
 	// GetItem 获取缓存项
 	GetItem(key string) (*Item, bool)
-	// AddItem 添加缓存项
+	// PeekItem 获取缓存项，不会改变LRU顺序
+	PeekItem(key string) (*Item, bool)
+	// AddItem 添加缓存项，写入时会设置val的Version和CreatedTime
+	// 调用后val归缓存所有，不能再修改，也不能添加到其他key
 	AddItem(key string, val *Item)
+	// AddItemIfVersion 仅当已存在的缓存项版本号与version一致时添加缓存项，val的所有权同AddItem
+	// 返回false表示缓存项不存在、已过期或版本号不一致
+	AddItemIfVersion(key string, val *Item, version uint64) bool
+	// UpdateItem 原子地读取并修改缓存项
+	// fn的参数为当前未过期的缓存项，不存在时为nil；fn返回新的缓存项，返回nil表示删除缓存项
+	// fn返回error时不做任何修改，并将error返回
+	// fn执行期间持有该key的锁，不会阻塞其他key的读写，不要在fn中操作缓存
+	// LRU缓存中fn执行期间对象被淘汰或批量删除时，会重新调用fn
+	UpdateItem(key string, fn func(old *Item) (*Item, error)) error
 	// RemoveItem 移除缓存项
 	RemoveItem(key string)
+	// GetItems 批量获取缓存项，返回的map中只包含存在的key
+	GetItems(keys []string) map[string]*Item
+	// AddItems 批量添加缓存项，缓存项的所有权同AddItem
+	AddItems(items map[string]*Item)
+	// RemoveItems 批量移除缓存项
+	RemoveItems(keys []string)
+	// RemoveItemsByTag 移除包含标签的所有缓存项，返回移除的数量
+	RemoveItemsByTag(tag string) int
+	// RemoveItemsByPrefix 移除key以prefix开头的所有缓存项，返回移除的数量
+	RemoveItemsByPrefix(prefix string) int
 	// Flush 清空缓存
 	Flush()
 	// Len 返回缓存对象数量
@@ -47,22 +216,119 @@ type ItemMap interface {
 	// Range 遍历缓存对象，接受一个op函数，函数参数分别是key/value
 	// 返回true表示继续遍历，返回false表示停止遍历
 	Range(op func(string, interface{}) bool)
+	// RangeItems 遍历缓存对象及其元信息，用法同Range
+	RangeItems(op func(string, ItemInfo) bool)
+	// RangePrefix 按key的字典序遍历key以prefix开头的缓存对象，用法同Range
+	RangePrefix(prefix string, op func(string, interface{}) bool)
+	// RangeBetween 按key的字典序遍历key在[start, end)范围内的缓存对象，end为空表示没有上限，用法同Range
+	RangeBetween(start, end string, op func(string, interface{}) bool)
+	// Scan 基于游标分批遍历缓存对象的key，返回匹配glob模式match的key和下一次遍历的游标
+	// 第一次遍历时cursor为0，返回的游标为0时表示遍历完成；count为每次遍历的key数量的参考值
+	// 两次调用之间不持有锁，遍历期间一直存在的key一定会被返回，且只会返回一次
+	Scan(cursor uint64, match string, count int) (keys []string, next uint64)
 	// ClearExpired 清空过期对象
 	ClearExpired()
 }
 
-var _ ItemMap = &itemMap{}
+// keyLockCount 分段锁的数量
+const keyLockCount = 256
+
+// keyLocks 按key分段的锁，保证同一个key的写操作串行执行
+type keyLocks [keyLockCount]sync.Mutex
+
+func (l *keyLocks) get(key string) *sync.Mutex {
+	return &l[hashKey(key)%keyLockCount]
+}
+
+// hashKey 计算key的FNV-1a哈希值
+func hashKey(key string) uint32 {
+	h := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= 16777619
+	}
+	return h
+}
+
+// lockAll 锁住所有key
+func (l *keyLocks) lockAll() {
+	for i := range l {
+		l[i].Lock()
+	}
+}
+
+func (l *keyLocks) unlockAll() {
+	for i := range l {
+		l[i].Unlock()
+	}
+}
+
+// lockKeys 按顺序锁住多个key对应的锁，避免死锁，返回解锁函数
+func (l *keyLocks) lockKeys(keys []string) (unlock func()) {
+	var set [keyLockCount]bool
+	for _, key := range keys {
+		set[hashKey(key)%keyLockCount] = true
+	}
+	for i := range set {
+		if set[i] {
+			l[i].Lock()
+		}
+	}
+	return func() {
+		for i := range set {
+			if set[i] {
+				l[i].Unlock()
+			}
+		}
+	}
+}
+
+// baseItemMap 可以被多个命名空间共享的底层ItemMap
+type baseItemMap interface {
+	ItemMap
+	// addIndex 添加索引，并将已有的缓存项加入索引
+	addIndex(index itemIndex)
+	// removeItemsByTag 移除包含标签且key满足match的所有缓存项，match为nil表示不过滤key
+	removeItemsByTag(tag string, match func(key string) bool) int
+	// removeItemsIf 移除满足match的所有缓存项，reason为移除的原因，返回移除的数量
+	removeItemsIf(match func(key string, item *Item) bool, reason EventOp) int
+	// orderedKeys 按字典序返回[start, end)范围内的key，end为空表示没有上限
+	orderedKeys(start, end string) []string
+	// scan 分批遍历满足match的key，match为nil表示不过滤key，用法同Scan
+	scan(cursor uint64, count int, match func(key string) bool) ([]string, uint64)
+	// now 返回时钟的当前时间
+	now() time.Time
+	// evictOldest 按优先级从低到高、最久未访问优先的顺序淘汰至多n个未固定的缓存项，返回淘汰的数量
+	evictOldest(n int) int
+}
+
+var _ baseItemMap = &itemMap{}
 
 type itemMap struct {
 	items     atomic.Value // 实际是*sync.Map类型
 	count     int64
-	deletedCb DeletedCallback
+	locks     keyLocks
+	callbacks *callbackDispatcher // 未设置删除回调时为nil
+	clock     Clock
+	tags      *tagIndex
+	keys      *keyIndex // 未启用有序key索引时为nil
+	scans     lazyScanIndex
+	indexes   itemIndexes
+	events    *eventHub
 }
 
-func newItemMap(deletedCb DeletedCallback) ItemMap {
+func newItemMap(options *Options, callbacks *callbackDispatcher) baseItemMap {
 	m := &itemMap{}
 	m.items.Store(&sync.Map{})
-	m.deletedCb = deletedCb
+	m.clock = clockOf(options)
+	m.callbacks = callbacks
+	m.events = newEventHub()
+	m.tags = newTagIndex()
+	m.indexes = itemIndexes{m.tags}
+	if options.KeyIndex {
+		m.keys = newKeyIndex()
+		m.indexes = append(m.indexes, m.keys)
+	}
 	return m
 }
 
@@ -80,33 +346,119 @@ func (m *itemMap) GetItem(key string) (*Item, bool) {
 }
 
 func (m *itemMap) AddItem(key string, val *Item) {
-	_, ok := m.getItems().Load(key)
-	if !ok {
-		atomic.AddInt64(&m.count, 1)
+	mu := m.locks.get(key)
+	mu.Lock()
+	defer mu.Unlock()
+	m.add(key, val)
+}
+
+func (m *itemMap) AddItemIfVersion(key string, val *Item, version uint64) bool {
+	mu := m.locks.get(key)
+	mu.Lock()
+	defer mu.Unlock()
+
+	// 已过期但尚未清理的缓存项视为不存在
+	old, ok := m.GetItem(key)
+	if !ok || old.Version != version || m.expired(old) {
+		return false
 	}
-	m.getItems().Store(key, val)
+	m.add(key, val)
+	return true
+}
+
+func (m *itemMap) UpdateItem(key string, fn func(old *Item) (*Item, error)) error {
+	removed, err := m.update(key, fn)
+	if removed != nil {
+		m.notifyDeleted(key, removed)
+	}
+	return err
+}
+
+// update 在持有key对应锁的情况下执行fn，返回被删除的缓存项
+func (m *itemMap) update(key string, fn func(old *Item) (*Item, error)) (*Item, error) {
+	mu := m.locks.get(key)
+	mu.Lock()
+	defer mu.Unlock()
+
+	old, ok := m.GetItem(key)
+	cur := old
+	if ok && m.expired(old) {
+		cur = nil
+	}
+	val, err := fn(cur)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case val == nil:
+		if ok {
+			m.remove(key, old, EventDelete)
+			return old, nil
+		}
+	case val != old:
+		m.add(key, val)
+	}
+	return nil, nil
 }
 
 func (m *itemMap) RemoveItem(key string) {
-	val, ok := m.getItems().Load(key)
-	if ok {
-		m.remove(key, val.(*Item))
+	m.removeIf(key, nil, EventDelete)
+}
+
+func (m *itemMap) GetItems(keys []string) map[string]*Item {
+	items := make(map[string]*Item, len(keys))
+	for _, key := range keys {
+		if item, ok := m.GetItem(key); ok {
+			items[key] = item
+		}
 	}
+	return items
+}
+
+func (m *itemMap) AddItems(items map[string]*Item) {
+	for key, val := range items {
+		m.AddItem(key, val)
+	}
+}
+
+func (m *itemMap) RemoveItems(keys []string) {
+	for _, key := range keys {
+		m.RemoveItem(key)
+	}
+}
+
+func (m *itemMap) RemoveItemsByTag(tag string) int {
+	return m.removeItemsByTag(tag, nil)
+}
+
+func (m *itemMap) RemoveItemsByPrefix(prefix string) int {
+	count := 0
+	for _, key := range m.orderedKeys(prefix, prefixEnd(prefix)) {
+		if m.removeIf(key, nil, EventDelete) {
+			count++
+		}
+	}
+	return count
 }
 
 func (m *itemMap) Flush() {
-	if m.deletedCb != nil {
+	if m.callbacks != nil {
 		// 逐个删除
-		m.getItems().Range(func(key, val interface{}) bool {
-			m.remove(key.(string), val.(*Item))
+		m.getItems().Range(func(key, _ interface{}) bool {
+			m.removeIf(key.(string), nil, EventFlush)
 			return true
 		})
 		return
 	}
 
 	// 直接替换新的map
+	m.locks.lockAll()
+	defer m.locks.unlockAll()
 	m.items.Store(&sync.Map{})
 	atomic.StoreInt64(&m.count, 0)
+	m.indexes.flush()
+	m.events.publish(EventFlush, "", nil, nil)
 }
 
 func (m *itemMap) Len() int {
@@ -117,14 +469,23 @@ func (m *itemMap) Range(op func(string, interface{}) bool) {
 	if op == nil {
 		return
 	}
+	m.RangeItems(func(key string, info ItemInfo) bool {
+		return op(key, info.Value)
+	})
+}
+
+func (m *itemMap) RangeItems(op func(string, ItemInfo) bool) {
+	if op == nil {
+		return
+	}
 
 	// sync.Map 的Range不会阻塞，可以放心执行
 	m.getItems().Range(func(key, val interface{}) bool {
 		item := val.(*Item)
-		if item.IsExpired() {
+		if item.isExpiredAt(m.now()) {
 			return true
 		}
-		if !op(key.(string), item.Value) {
+		if !op(key.(string), item.info()) {
 			// break
 			return false
 		}
@@ -132,22 +493,217 @@ func (m *itemMap) Range(op func(string, interface{}) bool) {
 	})
 }
 
+func (m *itemMap) RangePrefix(prefix string, op func(string, interface{}) bool) {
+	m.RangeBetween(prefix, prefixEnd(prefix), op)
+}
+
+func (m *itemMap) RangeBetween(start, end string, op func(string, interface{}) bool) {
+	if op == nil {
+		return
+	}
+	rangeOrdered(m, start, end, func(key string, info ItemInfo) bool {
+		return op(key, info.Value)
+	})
+}
+
+func (m *itemMap) Scan(cursor uint64, match string, count int) (keys []string, next uint64) {
+	return m.scan(cursor, count, func(key string) bool {
+		return globMatch(match, key)
+	})
+}
+
 func (m *itemMap) ClearExpired() {
+	m.removeItemsIf(func(_ string, item *Item) bool {
+		return item.isExpiredAt(m.now())
+	}, EventExpire)
+}
+
+func (m *itemMap) addIndex(index itemIndex) {
+	// 锁住所有key，保证加入索引期间没有写操作
+	m.locks.lockAll()
+	defer m.locks.unlockAll()
+
+	m.getItems().Range(func(key, val interface{}) bool {
+		index.onAdd(key.(string), nil, val.(*Item))
+		return true
+	})
+	indexes := make(itemIndexes, 0, len(m.indexes)+1)
+	m.indexes = append(append(indexes, m.indexes...), index)
+}
+
+func (m *itemMap) removeItemsByTag(tag string, match func(key string) bool) int {
+	count := 0
+	for _, key := range m.tags.keys(tag) {
+		if match != nil && !match(key) {
+			continue
+		}
+		// 再次确认缓存项包含标签，避免误删刚刚写入的新对象
+		if m.removeIf(key, func(item *Item) bool { return item.HasTag(tag) }, EventDelete) {
+			count++
+		}
+	}
+	return count
+}
+
+func (m *itemMap) removeItemsIf(match func(key string, item *Item) bool, reason EventOp) int {
+	count := 0
 	// sync.Map 的Range不会阻塞，可以放心执行
 	m.getItems().Range(func(key, val interface{}) bool {
-		item := val.(*Item)
-		if item.IsExpired() {
-			m.remove(key.(string), item)
+		k := key.(string)
+		if match(k, val.(*Item)) {
+			// 加锁后再次确认，避免误删刚刚写入的新对象
+			if m.removeIf(k, func(item *Item) bool { return match(k, item) }, reason) {
+				count++
+			}
+		}
+		return true
+	})
+	return count
+}
+
+func (m *itemMap) orderedKeys(start, end string) []string {
+	if m.keys != nil {
+		return m.keys.keys(start, end)
+	}
+
+	// 未启用索引时遍历所有key后排序
+	var keys []string
+	m.getItems().Range(func(key, _ interface{}) bool {
+		if k := key.(string); keyInRange(k, start, end) {
+			keys = append(keys, k)
 		}
 		return true
 	})
+	sort.Strings(keys)
+	return keys
 }
 
-func (m *itemMap) remove(key string, item *Item) {
+func (m *itemMap) PeekItem(key string) (*Item, bool) {
+	return m.GetItem(key)
+}
+
+func (m *itemMap) eventHub() (*eventHub, string) {
+	return m.events, ""
+}
+
+func (m *itemMap) scan(cursor uint64, count int, match func(key string) bool) ([]string, uint64) {
+	return scanItems(m, m.scans.get(m), cursor, count, match)
+}
+
+// removeIf 缓存项存在且满足条件时删除，cond为nil表示无条件删除，返回是否删除
+func (m *itemMap) removeIf(key string, cond func(*Item) bool, reason EventOp) bool {
+	mu := m.locks.get(key)
+	mu.Lock()
+	item, ok := m.GetItem(key)
+	ok = ok && (cond == nil || cond(item))
+	if ok {
+		m.remove(key, item, reason)
+	}
+	mu.Unlock()
+
+	if ok {
+		m.notifyDeleted(key, item)
+	}
+	return ok
+}
+
+func (m *itemMap) now() time.Time {
+	return m.clock.Now()
+}
+
+func (m *itemMap) evictOldest(n int) int {
+	if n <= 0 {
+		return 0
+	}
+
+	// 只保留最先被淘汰的n个候选对象，避免在内存压力下复制并排序所有对象
+	candidates := make(evictionHeap, 0, n)
+	m.getItems().Range(func(key, value interface{}) bool {
+		item := value.(*Item)
+		if item.Pinned {
+			return true
+		}
+		c := evictionCandidate{key: key.(string), item: item, accessed: item.lastAccessed()}
+		if len(candidates) < n {
+			heap.Push(&candidates, c)
+		} else if c.evictsBefore(&candidates[0]) {
+			candidates[0] = c
+			heap.Fix(&candidates, 0)
+		}
+		return true
+	})
+
+	count := 0
+	for _, c := range candidates {
+		item := c.item
+		if m.removeIf(c.key, func(cur *Item) bool { return cur == item }, eventEvictMemory) {
+			count++
+		}
+	}
+	return count
+}
+
+// evictionCandidate 内存压力下淘汰的候选对象
+type evictionCandidate struct {
+	key      string
+	item     *Item
+	accessed time.Time
+}
+
+// evictsBefore 是否比other先被淘汰，优先级低的先淘汰，优先级相同时最久未访问的先淘汰
+func (c *evictionCandidate) evictsBefore(other *evictionCandidate) bool {
+	if c.item.Priority != other.item.Priority {
+		return c.item.Priority < other.item.Priority
+	}
+	return c.accessed.Before(other.accessed)
+}
+
+// evictionHeap 候选对象的大顶堆，堆顶是候选对象中最后被淘汰的
+type evictionHeap []evictionCandidate
+
+func (h evictionHeap) Len() int            { return len(h) }
+func (h evictionHeap) Less(i, j int) bool  { return h[j].evictsBefore(&h[i]) }
+func (h evictionHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *evictionHeap) Push(x interface{}) { *h = append(*h, x.(evictionCandidate)) }
+func (h *evictionHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// expired 缓存项是否已过期，永不过期时不读取时钟
+func (m *itemMap) expired(item *Item) bool {
+	return item.ExpiredTime != nil && item.isExpiredAt(m.now())
+}
+
+// add 保存缓存项，调用前需持有key对应的锁
+func (m *itemMap) add(key string, val *Item) {
+	old, ok := m.GetItem(key)
+	if !ok {
+		atomic.AddInt64(&m.count, 1)
+	}
+	val.Version = nextVersion()
+	if val.CreatedTime.IsZero() {
+		val.CreatedTime = m.now()
+	}
+	m.getItems().Store(key, val)
+	m.indexes.add(key, old, val)
+	m.events.publish(EventSet, key, old, val)
+}
+
+// remove 删除缓存项，调用前需持有key对应的锁
+func (m *itemMap) remove(key string, item *Item, reason EventOp) {
 	m.getItems().Delete(key)
 	atomic.AddInt64(&m.count, -1)
+	reason = removeReason(item, reason, m.clock)
+	m.indexes.remove(key, item, reason)
+	m.events.publish(reason.public(), key, item, nil)
+}
 
-	if m.deletedCb != nil {
-		m.deletedCb(key, item.Value)
+// notifyDeleted 调用删除回调，不能在持有锁时调用，避免回调中操作缓存导致死锁
+func (m *itemMap) notifyDeleted(key string, item *Item) {
+	if m.callbacks != nil {
+		m.callbacks.dispatch(deletedEntry{key, item.Value})
 	}
 }
@@ -29,3 +29,13 @@ func TestItem(t *testing.T) {
 
 	assert.Equal(t, item.Value, num)
 }
+
+func TestItemWithDeadline(t *testing.T) {
+	deadline := time.Now().Add(time.Millisecond * 500)
+	item := cache.NewItemWithDeadline(rand.Int63(), deadline)
+	assert.Equal(t, *item.ExpiredTime, deadline)
+	assert.Equal(t, item.IsExpired(), false)
+
+	time.Sleep(time.Until(deadline) + time.Millisecond*100)
+	assert.Equal(t, item.IsExpired(), true)
+}
@@ -0,0 +1,219 @@
+package cache
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+var _ itemIndex = &keyIndex{}
+
+// keyIndex 基于基数树的有序key索引
+type keyIndex struct {
+	mu   sync.RWMutex
+	tree radixTree
+}
+
+func newKeyIndex() *keyIndex {
+	return &keyIndex{}
+}
+
+// keys 按字典序返回[start, end)范围内的key，end为空表示没有上限
+func (k *keyIndex) keys(start, end string) []string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	var keys []string
+	k.tree.walk(start, end, func(key string) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+func (k *keyIndex) onAdd(key string, old, _ *Item) {
+	if old != nil {
+		// key已经在索引中
+		return
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.tree.insert(key)
+}
+
+func (k *keyIndex) onRemove(key string, _ *Item, _ EventOp) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.tree.delete(key)
+}
+
+func (k *keyIndex) onFlush() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.tree = radixTree{}
+}
+
+// radixTree 基数树，子节点按首字节有序排列
+type radixTree struct {
+	root radixNode
+}
+
+type radixNode struct {
+	// prefix 从父节点到当前节点的路径
+	prefix string
+	// leaf 当前节点是否表示一个完整的key
+	leaf     bool
+	children []*radixNode
+}
+
+// child 查找首字节为c的子节点，不存在时返回应插入的位置
+func (n *radixNode) child(c byte) (int, *radixNode) {
+	i := sort.Search(len(n.children), func(i int) bool {
+		return n.children[i].prefix[0] >= c
+	})
+	if i < len(n.children) && n.children[i].prefix[0] == c {
+		return i, n.children[i]
+	}
+	return i, nil
+}
+
+// mergeChild 节点不是key且只有一个子节点时，与子节点合并
+func (n *radixNode) mergeChild() {
+	if n.leaf || len(n.children) != 1 {
+		return
+	}
+	child := n.children[0]
+	n.prefix += child.prefix
+	n.leaf = child.leaf
+	n.children = child.children
+}
+
+func (t *radixTree) insert(key string) {
+	n := &t.root
+	for {
+		if len(key) == 0 {
+			n.leaf = true
+			return
+		}
+
+		i, child := n.child(key[0])
+		if child == nil {
+			n.children = append(n.children, nil)
+			copy(n.children[i+1:], n.children[i:])
+			n.children[i] = &radixNode{prefix: key, leaf: true}
+			return
+		}
+
+		common := commonPrefixLen(key, child.prefix)
+		if common < len(child.prefix) {
+			// 拆分子节点
+			mid := &radixNode{
+				prefix:   child.prefix[:common],
+				children: []*radixNode{child},
+			}
+			child.prefix = child.prefix[common:]
+			n.children[i] = mid
+			child = mid
+		}
+		key = key[common:]
+		n = child
+	}
+}
+
+func (t *radixTree) delete(key string) {
+	var parent *radixNode
+	var index int
+	n := &t.root
+	for len(key) > 0 {
+		i, child := n.child(key[0])
+		if child == nil || !strings.HasPrefix(key, child.prefix) {
+			return
+		}
+		parent, index = n, i
+		key = key[len(child.prefix):]
+		n = child
+	}
+	if !n.leaf {
+		return
+	}
+	n.leaf = false
+
+	if parent == nil {
+		// 根节点
+		return
+	}
+	if len(n.children) == 0 {
+		parent.children = append(parent.children[:index], parent.children[index+1:]...)
+		if parent != &t.root {
+			parent.mergeChild()
+		}
+		return
+	}
+	n.mergeChild()
+}
+
+// walk 按字典序遍历[start, end)范围内的key，end为空表示没有上限
+func (t *radixTree) walk(start, end string, fn func(key string) bool) {
+	t.root.walk("", start, end, fn)
+}
+
+func (n *radixNode) walk(key, start, end string, fn func(key string) bool) bool {
+	key += n.prefix
+	if end != "" && key >= end {
+		// 后续的key都不小于end，停止遍历
+		return false
+	}
+	if key < start && !strings.HasPrefix(start, key) {
+		// 子树中的key都小于start
+		return true
+	}
+	if n.leaf && key >= start {
+		if !fn(key) {
+			return false
+		}
+	}
+	for _, child := range n.children {
+		if !child.walk(key, start, end, fn) {
+			return false
+		}
+	}
+	return true
+}
+
+func commonPrefixLen(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
+
+// prefixEnd 返回大于所有以prefix为前缀的key的最小字符串，不存在时返回空
+func prefixEnd(prefix string) string {
+	b := []byte(prefix)
+	for i := len(b) - 1; i >= 0; i-- {
+		if b[i] < 0xff {
+			b[i]++
+			return string(b[:i+1])
+		}
+	}
+	return ""
+}
+
+// keyInRange key是否在[start, end)范围内，end为空表示没有上限
+func keyInRange(key, start, end string) bool {
+	return key >= start && (end == "" || key < end)
+}
+
+// rangeOrdered 按字典序遍历[start, end)范围内未过期的缓存项，不会改变LRU顺序
+func rangeOrdered(m baseItemMap, start, end string, op func(string, ItemInfo) bool) {
+	for _, key := range m.orderedKeys(start, end) {
+		item, ok := m.PeekItem(key)
+		if !ok || item.isExpiredAt(m.now()) {
+			continue
+		}
+		if !op(key, item.info()) {
+			break
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package cache_test
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/Nomango/go-cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyIndex(t *testing.T) {
+	testFunc := func(t *testing.T, c cache.Cache) {
+		c.Set("user:1", 1)
+		c.Set("user:10", 10)
+		c.Set("user:2", 2)
+		c.Set("user:1:name", "n1")
+		c.Set("order:1", 1)
+		c.Set("user", 0)
+
+		rangeKeys := func(rangeFunc func(op func(string, interface{}) bool)) []string {
+			var keys []string
+			rangeFunc(func(key string, _ interface{}) bool {
+				keys = append(keys, key)
+				return true
+			})
+			return keys
+		}
+
+		// 按前缀有序遍历
+		keys := rangeKeys(func(op func(string, interface{}) bool) {
+			c.RangePrefix("user:1", op)
+		})
+		assert.Equal(t, keys, []string{"user:1", "user:10", "user:1:name"})
+
+		// 按范围有序遍历
+		keys = rangeKeys(func(op func(string, interface{}) bool) {
+			c.RangeBetween("user:10", "user:2", op)
+		})
+		assert.Equal(t, keys, []string{"user:10", "user:1:name"})
+		keys = rangeKeys(func(op func(string, interface{}) bool) {
+			c.RangeBetween("", "", op)
+		})
+		assert.Equal(t, keys, []string{"order:1", "user", "user:1", "user:10", "user:1:name", "user:2"})
+
+		// 提前停止遍历
+		count := 0
+		c.RangePrefix("user", func(string, interface{}) bool {
+			count++
+			return false
+		})
+		assert.Equal(t, count, 1)
+
+		// 按前缀删除
+		assert.Equal(t, c.DeletePrefix("user:1"), 3)
+		assert.Equal(t, c.Len(), 3)
+		keys = rangeKeys(func(op func(string, interface{}) bool) {
+			c.RangePrefix("", op)
+		})
+		assert.Equal(t, keys, []string{"order:1", "user", "user:2"})
+
+		// 命名空间
+		ns := c.Namespace("ns")
+		ns.Set("b", 2)
+		ns.Set("a", 1)
+		ns.Set("c", 3)
+		keys = rangeKeys(func(op func(string, interface{}) bool) {
+			ns.RangeBetween("b", "", op)
+		})
+		assert.Equal(t, keys, []string{"b", "c"})
+		assert.Equal(t, ns.DeletePrefix(""), 3)
+		assert.Equal(t, c.Len(), 3)
+
+		// 清空后索引同步清空
+		c.Flush()
+		keys = rangeKeys(func(op func(string, interface{}) bool) {
+			c.RangePrefix("", op)
+		})
+		assert.Equal(t, len(keys), 0)
+	}
+
+	for _, keyIndex := range []bool{true, false} {
+		t.Run(fmt.Sprintf("Cache KeyIndex=%v", keyIndex), func(t *testing.T) {
+			testFunc(t, cache.NewWithOptions(&cache.Options{KeyIndex: keyIndex}))
+		})
+		t.Run(fmt.Sprintf("LRUCache KeyIndex=%v", keyIndex), func(t *testing.T) {
+			testFunc(t, cache.NewWithOptions(&cache.Options{Capacity: 100, KeyIndex: keyIndex}))
+		})
+	}
+}
+
+func TestKeyIndexRandom(t *testing.T) {
+	options := &cache.Options{
+		Capacity: 500,
+		KeyIndex: true,
+	}
+	c := cache.NewWithOptions(options)
+
+	// 随机写入和删除，与LRU淘汰后的实际结果对比
+	alphabet := "ab:"
+	randomKey := func() string {
+		b := make([]byte, rand.Intn(6)+1)
+		for i := range b {
+			b[i] = alphabet[rand.Intn(len(alphabet))]
+		}
+		return string(b)
+	}
+	for i := 0; i < 5000; i++ {
+		if rand.Intn(3) == 0 {
+			c.Delete(randomKey())
+		} else {
+			c.Set(randomKey(), i)
+		}
+	}
+
+	var expected []string
+	c.Range(func(key string, _ interface{}) bool {
+		expected = append(expected, key)
+		return true
+	})
+	sort.Strings(expected)
+
+	var keys []string
+	c.RangePrefix("", func(key string, _ interface{}) bool {
+		keys = append(keys, key)
+		return true
+	})
+	assert.Equal(t, keys, expected)
+
+	for _, prefix := range []string{"a", "ab", "b:", ":a:"} {
+		var expectedPrefix []string
+		for _, key := range expected {
+			if strings.HasPrefix(key, prefix) {
+				expectedPrefix = append(expectedPrefix, key)
+			}
+		}
+		var prefixKeys []string
+		c.RangePrefix(prefix, func(key string, _ interface{}) bool {
+			prefixKeys = append(prefixKeys, key)
+			return true
+		})
+		assert.Equal(t, prefixKeys, expectedPrefix)
+	}
+}
@@ -2,18 +2,32 @@ package cache
 
 import (
 	"container/list"
+	"sort"
 	"sync"
+	"time"
 )
 
 type lruItemMap struct {
 	items map[string]*list.Element
 	mu    sync.RWMutex
-	// LRU缓存的容量
+	// locks 按key分段的锁，保证同一个key的写操作串行执行，UpdateItem执行fn时只持有key的锁
+	locks keyLocks
+	// LRU缓存的容量，不包含固定对象
 	capacity int
-	// LRU链表
-	list *list.List
+	// 固定对象的数量上限，为0时不限制
+	maxPinned int
+	// 按优先级分组的LRU链表
+	lists *lruLists
 
-	deletedCb DeletedCallback
+	callbacks *callbackDispatcher // 未设置删除回调时为nil
+	clock     Clock
+	// pending 持有写锁期间被删除的对象，释放锁后调用删除回调
+	pending []deletedEntry
+	tags    *tagIndex
+	keys    *keyIndex // 未启用有序key索引时为nil
+	scans   lazyScanIndex
+	indexes itemIndexes
+	events  *eventHub
 }
 
 // lruNode 链表节点
@@ -22,115 +36,343 @@ type lruNode struct {
 	item *Item
 }
 
-func newLRUItemMap(capacity int, deletedCb DeletedCallback) ItemMap {
-	return &lruItemMap{
-		items:     make(map[string]*list.Element, capacity),
-		capacity:  capacity,
-		list:      list.New(),
-		deletedCb: deletedCb,
+var _ baseItemMap = &lruItemMap{}
+
+func newLRUItemMap(options *Options, callbacks *callbackDispatcher) baseItemMap {
+	m := &lruItemMap{
+		items:     make(map[string]*list.Element, options.Capacity),
+		capacity:  options.Capacity,
+		maxPinned: options.MaxPinned,
+		lists:     newLRULists(),
+		callbacks: callbacks,
+		clock:     clockOf(options),
+		tags:      newTagIndex(),
+		events:    newEventHub(),
+	}
+	m.indexes = itemIndexes{m.tags}
+	if options.KeyIndex {
+		m.keys = newKeyIndex()
+		m.indexes = append(m.indexes, m.keys)
 	}
+	return m
 }
 
 func (m *lruItemMap) GetItem(key string) (*Item, bool) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	m.lock()
+	defer m.unlock()
+	return m.get(key)
+}
+
+// get 获取缓存项并移动到链表头，调用前需持有写锁
+func (m *lruItemMap) get(key string) (*Item, bool) {
 	elem, ok := m.items[key]
 	if ok {
 		// 将新访问的元素放到链表头
-		m.list.Remove(elem)
-		newElem := m.list.PushFront(elem.Value)
-		// 更新map中的elem
-		m.items[key] = newElem
-		return newElem.Value.(*lruNode).item, ok
+		m.lists.moveToFront(elem)
+		return elem.Value.(*lruNode).item, ok
 	}
 	return nil, false
 }
 
 func (m *lruItemMap) AddItem(key string, val *Item) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	mu := m.locks.get(key)
+	mu.Lock()
+	m.lock()
+	m.add(key, val)
+	pending := m.release()
+	mu.Unlock()
+	m.dispatch(pending)
+}
+
+func (m *lruItemMap) AddItemIfVersion(key string, val *Item, version uint64) bool {
+	mu := m.locks.get(key)
+	mu.Lock()
+	m.lock()
+	elem, ok := m.items[key]
+	if ok {
+		// 已过期但尚未清理的缓存项视为不存在
+		old := elem.Value.(*lruNode).item
+		ok = old.Version == version && !m.expired(old)
+	}
+	if ok {
+		m.add(key, val)
+	}
+	pending := m.release()
+	mu.Unlock()
+	m.dispatch(pending)
+	return ok
+}
 
+// add 保存缓存项，调用前需持有写锁
+func (m *lruItemMap) add(key string, val *Item) {
+	val.Version = nextVersion()
+	if val.CreatedTime.IsZero() {
+		val.CreatedTime = m.now()
+	}
 	oldElem, ok := m.items[key]
 
 	// 保存新节点
 	newNode := &lruNode{key: key, item: val}
-	elem := m.list.PushFront(newNode)
+	elem := m.lists.pushFront(newNode)
 	m.items[key] = elem
 
 	// 已经存在key，直接覆盖
+	var old *Item
 	if ok {
-		m.list.Remove(oldElem)
-		return
+		m.lists.remove(oldElem)
+		old = oldElem.Value.(*lruNode).item
+	}
+	m.indexes.add(key, old, val)
+	m.events.publish(EventSet, key, old, val)
+
+	// 覆盖时对象的固定状态可能改变，所以每次都检查容量
+	m.evict()
+}
+
+// evict 淘汰超出容量的对象并调用删除回调，调用前需持有写锁
+// 固定对象不占用容量，超过固定对象上限时淘汰最久未访问的固定对象
+// 未固定对象超过容量时，淘汰最低优先级中最久未访问的对象
+func (m *lruItemMap) evict() {
+	for m.maxPinned > 0 && m.lists.pinnedLen() > m.maxPinned {
+		m.removeElem(m.lists.pinned.Back(), EventEvict)
+	}
+	for len(m.items)-m.lists.pinnedLen() > m.capacity {
+		m.removeElem(m.lists.oldest(), EventEvict)
+	}
+}
+
+// removeElem 淘汰链表节点对应的对象，reason为淘汰的原因
+func (m *lruItemMap) removeElem(elem *list.Element, reason EventOp) {
+	m.remove(elem.Value.(*lruNode).key, elem, reason)
+}
+
+func (m *lruItemMap) now() time.Time {
+	return m.clock.Now()
+}
+
+// expired 缓存项是否已过期，永不过期时不读取时钟
+func (m *lruItemMap) expired(item *Item) bool {
+	return item.ExpiredTime != nil && item.isExpiredAt(m.now())
+}
+
+func (m *lruItemMap) evictOldest(n int) int {
+	m.lock()
+	defer m.unlock()
+
+	count := 0
+	for ; count < n && len(m.items) > m.lists.pinnedLen(); count++ {
+		m.removeElem(m.lists.oldest(), eventEvictMemory)
 	}
+	return count
+}
+
+// resize 修改容量，并立即淘汰超出新容量的对象
+func (m *lruItemMap) resize(capacity int) error {
+	m.lock()
+	defer m.unlock()
+	m.capacity = capacity
+	m.evict()
+	return nil
+}
+
+func (m *lruItemMap) UpdateItem(key string, fn func(old *Item) (*Item, error)) error {
+	mu := m.locks.get(key)
+	mu.Lock()
+	pending, err := m.update(key, fn)
+	mu.Unlock()
+	m.dispatch(pending)
+	return err
+}
+
+// update 在持有key对应锁的情况下执行fn，返回被删除的对象
+// 只在读取和写入时持有写锁，执行fn期间不会阻塞其他key的读写
+func (m *lruItemMap) update(key string, fn func(old *Item) (*Item, error)) ([]deletedEntry, error) {
+	for {
+		m.mu.RLock()
+		var current *Item
+		if elem, ok := m.items[key]; ok {
+			current = elem.Value.(*lruNode).item
+		}
+		m.mu.RUnlock()
 
-	// 不存在key，且超过容量
-	size := len(m.items)
-	if size > m.capacity {
-		// 移除最后一个
-		back := m.list.Back()
-		m.list.Remove(back)
-		delete(m.items, back.Value.(*lruNode).key)
+		old := current
+		if old != nil && m.expired(old) {
+			old = nil
+		}
+		val, err := fn(old)
+		if err != nil {
+			return nil, err
+		}
+
+		m.lock()
+		elem, ok := m.items[key]
+		if ok && elem.Value.(*lruNode).item != current || !ok && current != nil {
+			// 执行fn期间对象被淘汰或批量删除，重新读取
+			m.mu.Unlock()
+			continue
+		}
+		switch {
+		case val == nil:
+			if ok {
+				m.remove(key, elem, EventDelete)
+			}
+		case val != current:
+			m.add(key, val)
+		}
+		return m.release(), nil
 	}
 }
 
 func (m *lruItemMap) RemoveItem(key string) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	elem, ok := m.items[key]
-	if ok {
-		m.remove(key, elem)
+	mu := m.locks.get(key)
+	mu.Lock()
+	m.lock()
+	if elem, ok := m.items[key]; ok {
+		m.remove(key, elem, EventDelete)
+	}
+	pending := m.release()
+	mu.Unlock()
+	m.dispatch(pending)
+}
+
+func (m *lruItemMap) GetItems(keys []string) map[string]*Item {
+	m.lock()
+	defer m.unlock()
+
+	items := make(map[string]*Item, len(keys))
+	for _, key := range keys {
+		if item, ok := m.get(key); ok {
+			items[key] = item
+		}
+	}
+	return items
+}
+
+func (m *lruItemMap) AddItems(items map[string]*Item) {
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	unlockKeys := m.locks.lockKeys(keys)
+	m.lock()
+	for key, val := range items {
+		m.add(key, val)
+	}
+	pending := m.release()
+	unlockKeys()
+	m.dispatch(pending)
+}
+
+func (m *lruItemMap) RemoveItems(keys []string) {
+	unlockKeys := m.locks.lockKeys(keys)
+	m.lock()
+	for _, key := range keys {
+		if elem, ok := m.items[key]; ok {
+			m.remove(key, elem, EventDelete)
+		}
+	}
+	pending := m.release()
+	unlockKeys()
+	m.dispatch(pending)
+}
+
+func (m *lruItemMap) RemoveItemsByTag(tag string) int {
+	return m.removeItemsByTag(tag, nil)
+}
+
+func (m *lruItemMap) RemoveItemsByPrefix(prefix string) int {
+	keys := m.orderedKeys(prefix, prefixEnd(prefix))
+
+	m.lock()
+	defer m.unlock()
+	count := 0
+	for _, key := range keys {
+		if elem, ok := m.items[key]; ok {
+			m.remove(key, elem, EventDelete)
+			count++
+		}
 	}
+	return count
 }
 
 func (m *lruItemMap) Flush() {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	m.lock()
+	defer m.unlock()
 
-	if m.deletedCb != nil {
+	if m.callbacks != nil {
 		// 逐个删除
 		for key, elem := range m.items {
-			m.remove(key, elem)
+			m.remove(key, elem, EventFlush)
 		}
 		return
 	}
 
 	// 直接替换新的map
 	m.items = make(map[string]*list.Element)
-	m.list = list.New()
+	m.lists.init()
+	m.indexes.flush()
+	m.events.publish(EventFlush, "", nil, nil)
 }
 
 func (m *lruItemMap) Len() int {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	return m.list.Len()
+	return len(m.items)
 }
 
 func (m *lruItemMap) Range(op func(string, interface{}) bool) {
 	if op == nil {
 		return
 	}
+	m.RangeItems(func(key string, info ItemInfo) bool {
+		return op(key, info.Value)
+	})
+}
+
+func (m *lruItemMap) RangeItems(op func(string, ItemInfo) bool) {
+	if op == nil {
+		return
+	}
 
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	for elem := m.list.Front(); elem != nil; elem = elem.Next() {
-		node := elem.Value.(*lruNode)
-		if node.item.IsExpired() {
-			continue
-		}
-		if !op(node.key, node.item.Value) {
-			break
+	now := m.now()
+	m.lists.each(func(node *lruNode) bool {
+		if node.item.isExpiredAt(now) {
+			return true
 		}
+		return op(node.key, node.item.info())
+	})
+}
+
+func (m *lruItemMap) RangePrefix(prefix string, op func(string, interface{}) bool) {
+	m.RangeBetween(prefix, prefixEnd(prefix), op)
+}
+
+func (m *lruItemMap) RangeBetween(start, end string, op func(string, interface{}) bool) {
+	if op == nil {
+		return
 	}
+	rangeOrdered(m, start, end, func(key string, info ItemInfo) bool {
+		return op(key, info.Value)
+	})
+}
+
+func (m *lruItemMap) Scan(cursor uint64, match string, count int) (keys []string, next uint64) {
+	return m.scan(cursor, count, func(key string) bool {
+		return globMatch(match, key)
+	})
 }
 
 func (m *lruItemMap) ClearExpired() {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	m.lock()
+	defer m.unlock()
 	count := 0
+	now := m.now()
 	for key, elem := range m.items {
 		node := elem.Value.(*lruNode)
-		if node.item.IsExpired() {
-			m.remove(key, elem)
+		if node.item.isExpiredAt(now) {
+			m.remove(key, elem, EventExpire)
 			count++
 		}
 
@@ -149,13 +391,125 @@ func (m *lruItemMap) ClearExpired() {
 	}
 }
 
-func (m *lruItemMap) remove(key string, elem *list.Element) {
-	removedNode := elem.Value.(*lruNode)
-	m.list.Remove(elem)
+func (m *lruItemMap) addIndex(index itemIndex) {
+	m.lock()
+	defer m.unlock()
 
-	delete(m.items, key)
+	for key, elem := range m.items {
+		index.onAdd(key, nil, elem.Value.(*lruNode).item)
+	}
+	m.indexes = append(m.indexes, index)
+}
+
+func (m *lruItemMap) removeItemsByTag(tag string, match func(key string) bool) int {
+	m.lock()
+	defer m.unlock()
+
+	count := 0
+	for _, key := range m.tags.keys(tag) {
+		if match != nil && !match(key) {
+			continue
+		}
+		if elem, ok := m.items[key]; ok {
+			m.remove(key, elem, EventDelete)
+			count++
+		}
+	}
+	return count
+}
+
+func (m *lruItemMap) removeItemsIf(match func(key string, item *Item) bool, reason EventOp) int {
+	m.lock()
+	defer m.unlock()
+
+	count := 0
+	for key, elem := range m.items {
+		if match(key, elem.Value.(*lruNode).item) {
+			m.remove(key, elem, reason)
+			count++
+		}
+	}
+	return count
+}
+
+func (m *lruItemMap) orderedKeys(start, end string) []string {
+	if m.keys != nil {
+		return m.keys.keys(start, end)
+	}
+
+	// 未启用索引时遍历所有key后排序
+	m.mu.RLock()
+	var keys []string
+	for key := range m.items {
+		if keyInRange(key, start, end) {
+			keys = append(keys, key)
+		}
+	}
+	m.mu.RUnlock()
+	sort.Strings(keys)
+	return keys
+}
+
+func (m *lruItemMap) PeekItem(key string) (*Item, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	elem, ok := m.items[key]
+	if !ok {
+		return nil, false
+	}
+	return elem.Value.(*lruNode).item, true
+}
+
+func (m *lruItemMap) eventHub() (*eventHub, string) {
+	return m.events, ""
+}
 
-	if m.deletedCb != nil {
-		m.deletedCb(key, removedNode.item.Value)
+func (m *lruItemMap) scan(cursor uint64, count int, match func(key string) bool) ([]string, uint64) {
+	return scanItems(m, m.scans.get(m), cursor, count, match)
+}
+
+// lock 获取写锁
+func (m *lruItemMap) lock() {
+	m.mu.Lock()
+}
+
+// unlock 释放写锁，然后调用持有锁期间产生的删除回调
+func (m *lruItemMap) unlock() {
+	m.dispatch(m.release())
+}
+
+// release 释放写锁，返回持有锁期间被删除的对象
+// 同时持有key的锁时，需要在释放key的锁之后再调用dispatch，避免回调中操作同一个key时死锁
+func (m *lruItemMap) release() []deletedEntry {
+	pending := m.pending
+	m.pending = nil
+	m.mu.Unlock()
+	return pending
+}
+
+// dispatch 调用删除回调
+func (m *lruItemMap) dispatch(pending []deletedEntry) {
+	if len(pending) > 0 {
+		m.callbacks.dispatch(pending...)
 	}
 }
+
+func (m *lruItemMap) remove(key string, elem *list.Element, reason EventOp) {
+	removedNode := m.unlink(key, elem, reason)
+
+	if m.callbacks != nil {
+		m.pending = append(m.pending, deletedEntry{key, removedNode.item.Value})
+	}
+}
+
+// unlink 从链表和索引中删除节点，不调用删除回调
+func (m *lruItemMap) unlink(key string, elem *list.Element, reason EventOp) *lruNode {
+	removedNode := elem.Value.(*lruNode)
+	m.lists.remove(elem)
+
+	delete(m.items, key)
+	reason = removeReason(removedNode.item, reason, m.clock)
+	m.indexes.remove(key, removedNode.item, reason)
+	m.events.publish(reason.public(), key, removedNode.item, nil)
+	return removedNode
+}
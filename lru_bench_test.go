@@ -44,6 +44,26 @@ func BenchmarkLRUCacheConcurrent(b *testing.B) {
 	})
 }
 
+func BenchmarkLRUCachePinned(b *testing.B) {
+	// 测试存在大量固定对象和高优先级对象时cache.Set的性能，淘汰不需要遍历这些对象
+	options := &cache.Options{
+		Capacity: 10000,
+	}
+	c := cache.NewWithOptions(options)
+	for i := 0; i < 200000; i++ {
+		c.SetWithOptions(fmt.Sprintf("pinned%d", i), i, cache.ItemOptions{Pinned: true})
+	}
+	for i := 0; i < 5000; i++ {
+		c.SetWithOptions(fmt.Sprintf("high%d", i), i, cache.ItemOptions{Priority: 1})
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Set(fmt.Sprintf("%d", i), i)
+	}
+}
+
 func BenchmarkLRUCacheCleanUp(b *testing.B) {
 	// 测试cache.CleanUp性能
 	testFunc := func(b *testing.B, capacity int) {
@@ -2,6 +2,7 @@ package cache_test
 
 import (
 	"math/rand"
+	"strconv"
 	"testing"
 	"time"
 
@@ -209,3 +210,209 @@ func TestLRUCacheWithCallback(t *testing.T) {
 	c.Flush()
 	assert.Equal(t, count, 0)
 }
+
+func TestLRUCacheEvictCallback(t *testing.T) {
+	var deleted []string
+	c := cache.NewWithOptions(&cache.Options{
+		Capacity: 1,
+		DeletedCallback: func(key string, v interface{}) {
+			deleted = append(deleted, key)
+		},
+	})
+
+	// 超出容量被淘汰时调用删除回调
+	c.Set("key1", 1)
+	c.Set("key2", 2)
+	assert.Equal(t, deleted, []string{"key1"})
+	c.SetMulti(map[string]interface{}{"key3": 3}, cache.NoExpiration)
+	assert.Equal(t, deleted, []string{"key1", "key2"})
+}
+
+func TestLRUCacheVersion(t *testing.T) {
+	options := &cache.Options{
+		Capacity: 2,
+	}
+	c := cache.NewWithOptions(options)
+
+	c.Set("key1", 1)
+	c.Set("key2", 2)
+	_, version, _ := c.GetWithVersion("key1")
+
+	// 访问key1会将其移动到链表头，版本号不变
+	_, _ = c.Get("key1")
+	_, _ = c.Get("key2")
+	value, newVersion, found := c.GetWithVersion("key1")
+	assert.Equal(t, found, true)
+	assert.Equal(t, value, 1)
+	assert.Equal(t, newVersion, version)
+
+	ok := c.SetIfVersion("key1", 10, version, cache.NoExpiration)
+	assert.Equal(t, ok, true)
+	ok = c.SetIfVersion("key1", 20, version, cache.NoExpiration)
+	assert.Equal(t, ok, false)
+	value, _ = c.Get("key1")
+	assert.Equal(t, value, 10)
+	assert.Equal(t, c.Len(), 2)
+
+	// 遍历时可以获取版本号
+	_, version, _ = c.GetWithVersion("key1")
+	c.RangeItems(func(key string, info cache.ItemInfo) bool {
+		if key == "key1" {
+			assert.Equal(t, info.Version, version)
+		}
+		return true
+	})
+}
+
+func TestLRUCacheInspect(t *testing.T) {
+	options := &cache.Options{
+		Capacity: 2,
+	}
+	c := cache.NewWithOptions(options)
+
+	c.Set("key1", 1)
+	c.Set("key2", 2)
+
+	// Inspect不会将key1移动到链表头
+	info, found := c.Inspect("key1")
+	assert.Equal(t, found, true)
+	assert.Equal(t, info.Value, 1)
+	c.Set("key3", 3)
+	_, found = c.Inspect("key1")
+	assert.Equal(t, found, false)
+}
+
+func TestLRUCachePeek(t *testing.T) {
+	options := &cache.Options{
+		Capacity: 2,
+	}
+	c := cache.NewWithOptions(options)
+
+	c.Set("key1", 1)
+	c.Set("key2", 2)
+
+	// Peek和Contains不会将key1移动到链表头
+	value, found := c.Peek("key1")
+	assert.Equal(t, found, true)
+	assert.Equal(t, value, 1)
+	assert.Equal(t, c.Contains("key1"), true)
+	c.Set("key3", 3)
+	assert.Equal(t, c.Contains("key1"), false)
+	_, found = c.Peek("key1")
+	assert.Equal(t, found, false)
+
+	// Peek和Contains不会删除过期对象
+	c.SetWithExpiration("key4", 4, time.Millisecond*100)
+	time.Sleep(time.Millisecond * 200)
+	assert.Equal(t, c.Contains("key4"), false)
+	_, found = c.Peek("key4")
+	assert.Equal(t, found, false)
+	assert.Equal(t, c.Len(), 2)
+
+	// Peek不会记录访问
+	_, _ = c.Peek("key3")
+	info, _ := c.Inspect("key3")
+	assert.Equal(t, info.Hits, uint64(0))
+	assert.Equal(t, c.Stats().Hits, uint64(0))
+}
+
+func TestLRUCacheTTL(t *testing.T) {
+	c := cache.NewWithOptions(&cache.Options{Capacity: 2})
+
+	c.Set("key1", 1)
+	c.Set("key2", 2)
+
+	// TTL不会将key1移动到链表头
+	ttl, found := c.TTL("key1")
+	assert.Equal(t, found, true)
+	assert.Equal(t, ttl, cache.NoTTL)
+	c.Set("key3", 3)
+	_, found = c.TTL("key1")
+	assert.Equal(t, found, false)
+	assert.Equal(t, c.Stats().Hits, uint64(0))
+}
+
+func TestLRUPinned(t *testing.T) {
+	c := cache.NewWithOptions(&cache.Options{Capacity: 2, MaxPinned: 2})
+
+	c.SetWithOptions("config1", 1, cache.ItemOptions{Pinned: true})
+	c.SetWithOptions("config2", 2, cache.ItemOptions{Pinned: true})
+	for i := 0; i < 10; i++ {
+		c.Set(strconv.Itoa(i), i)
+	}
+
+	// 固定对象不占用容量，不会被淘汰
+	assert.Equal(t, c.Len(), 4)
+	assert.Equal(t, c.Contains("config1"), true)
+	assert.Equal(t, c.Contains("config2"), true)
+	assert.Equal(t, c.Contains("8"), true)
+	assert.Equal(t, c.Contains("9"), true)
+
+	info, _ := c.Inspect("config1")
+	assert.Equal(t, info.Pinned, true)
+
+	// 超过固定对象上限时淘汰最久未访问的固定对象
+	c.Get("config1")
+	c.SetWithOptions("config3", 3, cache.ItemOptions{Pinned: true})
+	assert.Equal(t, c.Contains("config1"), true)
+	assert.Equal(t, c.Contains("config2"), false)
+	assert.Equal(t, c.Contains("config3"), true)
+
+	// 取消固定后占用容量
+	c.Set("config1", 1)
+	assert.Equal(t, c.Len(), 3)
+	assert.Equal(t, c.Contains("8"), false)
+}
+
+func TestLRUPriority(t *testing.T) {
+	c := cache.NewWithOptions(&cache.Options{Capacity: 3})
+
+	c.SetWithOptions("high", 1, cache.ItemOptions{Priority: 10})
+	c.SetWithOptions("low", 2, cache.ItemOptions{Priority: -1})
+	c.Set("normal1", 3)
+	c.Set("normal2", 4)
+
+	// 优先淘汰低优先级的对象
+	assert.Equal(t, c.Contains("low"), false)
+	assert.Equal(t, c.Contains("high"), true)
+
+	// 同优先级淘汰最久未访问的对象
+	c.Set("normal3", 5)
+	assert.Equal(t, c.Contains("normal1"), false)
+	assert.Equal(t, c.Contains("high"), true)
+
+	// 没有更低优先级的对象时，新对象自身被淘汰
+	c.SetWithOptions("low", 2, cache.ItemOptions{Priority: -1, TTL: time.Minute})
+	assert.Equal(t, c.Contains("low"), false)
+	assert.Equal(t, c.Len(), 3)
+}
+
+func TestLRUResize(t *testing.T) {
+	var deleted []string
+	c := cache.NewWithOptions(&cache.Options{
+		Capacity: 4,
+		DeletedCallback: func(key string, v interface{}) {
+			deleted = append(deleted, key)
+		},
+	})
+	for i := 0; i < 4; i++ {
+		c.Set(strconv.Itoa(i), i)
+	}
+
+	// 缩小容量时立即淘汰最久未访问的对象
+	assert.Equal(t, c.Resize(2), nil)
+	assert.Equal(t, c.Len(), 2)
+	assert.Equal(t, deleted, []string{"0", "1"})
+
+	// 扩大容量
+	assert.Equal(t, c.Resize(3), nil)
+	c.Set("4", 4)
+	assert.Equal(t, c.Len(), 3)
+
+	// 命名空间共享父缓存的容量
+	assert.Equal(t, c.Namespace("ns").Resize(1), nil)
+	assert.Equal(t, c.Len(), 1)
+
+	assert.Equal(t, c.Resize(0), cache.ErrInvalidCapacity)
+	assert.Equal(t, cache.New().Resize(1), cache.ErrNotBounded)
+}
@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"runtime"
+	"runtime/metrics"
+	"time"
+)
+
+// heapObjectsMetric 堆上对象占用的内存
+const heapObjectsMetric = "/memory/classes/heap/objects:bytes"
+
+// MemoryPressure 内存压力信息
+type MemoryPressure struct {
+	// HeapBytes 检测到内存压力时堆上对象占用的内存
+	HeapBytes uint64
+	// HeapBytesAfter 淘汰对象并执行GC后堆上对象占用的内存
+	HeapBytesAfter uint64
+	// Limit 内存软上限
+	Limit uint64
+	// Evicted 淘汰的对象数量
+	Evicted int
+}
+
+// memoryMonitor 定时检查堆内存，超过软上限时淘汰对象直到低于低水位
+type memoryMonitor struct {
+	limit        uint64
+	lowWatermark uint64
+	interval     time.Duration
+	gcRounds     int
+	stop         chan struct{}
+}
+
+func newMemoryMonitor(cache *cache, options *Options) *memoryMonitor {
+	m := &memoryMonitor{
+		limit:        options.MemoryLimit,
+		lowWatermark: options.MemoryLowWatermark,
+		interval:     options.MemoryCheckInterval,
+		gcRounds:     options.MemoryGCRounds,
+		stop:         make(chan struct{}),
+	}
+	if m.lowWatermark == 0 || m.lowWatermark > m.limit {
+		m.lowWatermark = m.limit / 10 * 9
+	}
+	if m.interval <= 0 {
+		m.interval = DefaultMemoryCheckInterval
+	}
+	if m.gcRounds <= 0 {
+		m.gcRounds = DefaultMemoryGCRounds
+	}
+	go m.Run(cache)
+	return m
+}
+
+func (m *memoryMonitor) Run(cache *cache) {
+	t := time.NewTicker(m.interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			m.check(cache)
+		case <-m.stop:
+			return
+		}
+	}
+}
+
+func (m *memoryMonitor) Stop() {
+	close(m.stop)
+}
+
+// check 堆内存超过软上限时，按比例分批淘汰对象，每批淘汰后执行GC并重新采样，最多执行gcRounds批
+// 没有淘汰任何对象时（例如缓存为空或全部是固定对象）不分发EventPressure事件
+func (m *memoryMonitor) check(cache *cache) {
+	heap := readHeapObjects()
+	if heap <= m.limit {
+		return
+	}
+
+	items := cache.ItemMap.(baseItemMap)
+	pressure := MemoryPressure{HeapBytes: heap, HeapBytesAfter: heap, Limit: m.limit}
+	for i := 0; i < m.gcRounds && pressure.HeapBytesAfter > m.lowWatermark; i++ {
+		// 淘汰的比例与超出低水位的比例相同
+		size := uint64(items.Len())
+		n := int((size*(pressure.HeapBytesAfter-m.lowWatermark) + pressure.HeapBytesAfter - 1) / pressure.HeapBytesAfter)
+		evicted := items.evictOldest(n)
+		if evicted == 0 {
+			break
+		}
+		pressure.Evicted += evicted
+
+		runtime.GC()
+		pressure.HeapBytesAfter = readHeapObjects()
+	}
+
+	if pressure.Evicted == 0 {
+		return
+	}
+	hub, _ := items.(eventSource).eventHub()
+	hub.publishPressure(pressure)
+}
+
+// readHeapObjects 读取堆上对象占用的内存，不支持该指标时返回0
+func readHeapObjects() uint64 {
+	samples := []metrics.Sample{{Name: heapObjectsMetric}}
+	metrics.Read(samples)
+	if samples[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return samples[0].Value.Uint64()
+}
@@ -0,0 +1,56 @@
+package cache_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Nomango/go-cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryPressure(t *testing.T) {
+	testFunc := func(t *testing.T, capacity int) {
+		// 软上限设置为1字节，每次检查都会淘汰所有未固定的对象
+		c := cache.NewWithOptions(&cache.Options{
+			Capacity:            capacity,
+			MemoryLimit:         1,
+			MemoryCheckInterval: time.Millisecond * 10,
+			MemoryGCRounds:      2,
+		})
+		events, cancel := c.Subscribe(cache.EventFilter{Ops: []cache.EventOp{cache.EventPressure}}, 10)
+		defer cancel()
+
+		c.SetWithOptions("config", 1, cache.ItemOptions{Pinned: true})
+		for i := 0; i < 100; i++ {
+			c.Set(strconv.Itoa(i), make([]byte, 1024))
+		}
+
+		// 写入期间可能已经触发了检查，累计淘汰的数量
+		evicted := 0
+		for evicted < 100 {
+			select {
+			case e := <-events:
+				assert.Equal(t, e.Op, cache.EventPressure)
+				assert.Equal(t, e.Pressure.Limit, uint64(1))
+				evicted += e.Pressure.Evicted
+			case <-time.After(time.Second * 5):
+				t.Fatal("no pressure event")
+			}
+		}
+		assert.Equal(t, evicted, 100)
+
+		// 固定对象不会被淘汰
+		assert.Equal(t, c.Len(), 1)
+		assert.Equal(t, c.Contains("config"), true)
+
+		// 只剩固定对象时没有可淘汰的对象，不再分发事件
+		select {
+		case e := <-events:
+			t.Fatalf("unexpected pressure event: %+v", e.Pressure)
+		case <-time.After(time.Millisecond * 50):
+		}
+	}
+	t.Run("Cache", func(t *testing.T) { testFunc(t, 0) })
+	t.Run("LRUCache", func(t *testing.T) { testFunc(t, 1000) })
+}
@@ -0,0 +1,346 @@
+package cache
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// namespaceSeparator 命名空间与key之间的分隔符
+	namespaceSeparator = "\x00"
+	// namespaceEscape 命名空间名称中的转义字符
+	namespaceEscape = "\x01"
+)
+
+// namespaceEscaper 转义后的名称中不包含分隔符
+var namespaceEscaper = strings.NewReplacer(namespaceEscape, namespaceEscape+"\x01", namespaceSeparator, namespaceEscape+"\x02")
+
+func (c *cache) Namespace(name string) Cache {
+	c.nsMu.Lock()
+	defer c.nsMu.Unlock()
+
+	if ns, ok := c.namespaces[name]; ok {
+		return ns
+	}
+	if c.namespaces == nil {
+		c.namespaces = make(map[string]*cache)
+	}
+	m := newPrefixItemMap(c.ItemMap, name, c.counters)
+	ns := &cache{
+		ItemMap:  m,
+		options:  c.options,
+		settings: c.settings,
+		clock:    c.clock,
+		usage:    m.counter,
+		counters: c.counters,
+	}
+	ns.hotKeys = newHotKeyTracker(c.options, c.now())
+	c.namespaces[name] = ns
+	return ns
+}
+
+// Close 命名空间与父缓存共享后台协程，不做任何事
+func (c *cache) Close() {}
+
+func (w *cacheWapper) Namespace(name string) Cache {
+	return &namespaceWapper{w.Cache.Namespace(name), w}
+}
+
+// namespaceWapper 命名空间包装器，持有父缓存的包装器，避免命名空间仍在使用时cleaner被停止
+type namespaceWapper struct {
+	Cache
+	parent *cacheWapper
+}
+
+func (w *namespaceWapper) Namespace(name string) Cache {
+	return &namespaceWapper{w.Cache.Namespace(name), w.parent}
+}
+
+var _ ItemMap = &prefixItemMap{}
+
+// prefixItemMap 命名空间的ItemMap，将所有key加上前缀后保存在底层的ItemMap中
+type prefixItemMap struct {
+	base    baseItemMap
+	prefix  string
+	counter *prefixCounter
+}
+
+func newPrefixItemMap(parent ItemMap, name string, counters *namespaceCounters) *prefixItemMap {
+	m := &prefixItemMap{}
+	name = escapeNamespace(name)
+	if p, ok := parent.(*prefixItemMap); ok {
+		// 嵌套的命名空间直接使用最底层的ItemMap
+		m.base = p.base
+		m.prefix = p.prefix + name + namespaceSeparator
+	} else {
+		m.base = parent.(baseItemMap)
+		m.prefix = name + namespaceSeparator
+	}
+	m.counter = counters.get(m.prefix)
+	return m
+}
+
+func (m *prefixItemMap) GetItem(key string) (*Item, bool) {
+	return m.base.GetItem(m.prefix + key)
+}
+
+func (m *prefixItemMap) PeekItem(key string) (*Item, bool) {
+	return m.base.PeekItem(m.prefix + key)
+}
+
+func (m *prefixItemMap) AddItem(key string, val *Item) {
+	m.base.AddItem(m.prefix+key, val)
+}
+
+func (m *prefixItemMap) AddItemIfVersion(key string, val *Item, version uint64) bool {
+	return m.base.AddItemIfVersion(m.prefix+key, val, version)
+}
+
+func (m *prefixItemMap) UpdateItem(key string, fn func(old *Item) (*Item, error)) error {
+	return m.base.UpdateItem(m.prefix+key, fn)
+}
+
+func (m *prefixItemMap) RemoveItem(key string) {
+	m.base.RemoveItem(m.prefix + key)
+}
+
+func (m *prefixItemMap) GetItems(keys []string) map[string]*Item {
+	items := m.base.GetItems(m.withPrefix(keys))
+	result := make(map[string]*Item, len(items))
+	for key, item := range items {
+		result[strings.TrimPrefix(key, m.prefix)] = item
+	}
+	return result
+}
+
+func (m *prefixItemMap) AddItems(items map[string]*Item) {
+	prefixed := make(map[string]*Item, len(items))
+	for key, item := range items {
+		prefixed[m.prefix+key] = item
+	}
+	m.base.AddItems(prefixed)
+}
+
+func (m *prefixItemMap) RemoveItems(keys []string) {
+	m.base.RemoveItems(m.withPrefix(keys))
+}
+
+func (m *prefixItemMap) RemoveItemsByTag(tag string) int {
+	return m.base.removeItemsByTag(tag, m.hasPrefix)
+}
+
+func (m *prefixItemMap) RemoveItemsByPrefix(prefix string) int {
+	return m.base.RemoveItemsByPrefix(m.prefix + prefix)
+}
+
+func (m *prefixItemMap) Flush() {
+	m.base.removeItemsIf(func(key string, _ *Item) bool {
+		return m.hasPrefix(key)
+	}, EventFlush)
+}
+
+func (m *prefixItemMap) Len() int {
+	return int(atomic.LoadInt64(&m.counter.count))
+}
+
+func (m *prefixItemMap) Range(op func(string, interface{}) bool) {
+	if op == nil {
+		return
+	}
+	m.RangeItems(func(key string, info ItemInfo) bool {
+		return op(key, info.Value)
+	})
+}
+
+func (m *prefixItemMap) RangeItems(op func(string, ItemInfo) bool) {
+	if op == nil {
+		return
+	}
+	m.base.RangeItems(func(key string, info ItemInfo) bool {
+		if !m.hasPrefix(key) {
+			return true
+		}
+		return op(strings.TrimPrefix(key, m.prefix), info)
+	})
+}
+
+func (m *prefixItemMap) RangePrefix(prefix string, op func(string, interface{}) bool) {
+	m.RangeBetween(prefix, prefixEnd(prefix), op)
+}
+
+func (m *prefixItemMap) RangeBetween(start, end string, op func(string, interface{}) bool) {
+	if op == nil {
+		return
+	}
+	baseEnd := prefixEnd(m.prefix)
+	if end != "" {
+		baseEnd = m.prefix + end
+	}
+	m.base.RangeBetween(m.prefix+start, baseEnd, func(key string, value interface{}) bool {
+		return op(strings.TrimPrefix(key, m.prefix), value)
+	})
+}
+
+func (m *prefixItemMap) Scan(cursor uint64, match string, count int) (keys []string, next uint64) {
+	keys, next = m.base.scan(cursor, count, func(key string) bool {
+		return m.hasPrefix(key) && globMatch(match, key[len(m.prefix):])
+	})
+	for i, key := range keys {
+		keys[i] = key[len(m.prefix):]
+	}
+	return keys, next
+}
+
+func (m *prefixItemMap) ClearExpired() {
+	m.base.removeItemsIf(func(key string, item *Item) bool {
+		return m.hasPrefix(key) && item.isExpiredAt(m.base.now())
+	}, EventExpire)
+}
+
+func (m *prefixItemMap) resize(capacity int) error {
+	if r, ok := m.base.(resizer); ok {
+		return r.resize(capacity)
+	}
+	return ErrNotBounded
+}
+
+func (m *prefixItemMap) eventHub() (*eventHub, string) {
+	hub, _ := m.base.(eventSource).eventHub()
+	return hub, m.prefix
+}
+
+func (m *prefixItemMap) hasPrefix(key string) bool {
+	return strings.HasPrefix(key, m.prefix)
+}
+
+func (m *prefixItemMap) withPrefix(keys []string) []string {
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = m.prefix + key
+	}
+	return prefixed
+}
+
+var _ itemIndex = &namespaceCounters{}
+
+// namespaceCounters 按命名空间的前缀统计缓存对象，root统计所有对象
+// 写入时按key中的分隔符找到key所属的各级命名空间的计数器，开销与命名空间的数量无关
+type namespaceCounters struct {
+	root *prefixCounter
+
+	mu       sync.RWMutex
+	counters map[string]*prefixCounter
+}
+
+func newNamespaceCounters() *namespaceCounters {
+	return &namespaceCounters{
+		root:     &prefixCounter{},
+		counters: make(map[string]*prefixCounter),
+	}
+}
+
+// get 获取前缀对应的计数器，不存在时创建
+func (n *namespaceCounters) get(prefix string) *prefixCounter {
+	if prefix == "" {
+		return n.root
+	}
+	n.mu.RLock()
+	c, ok := n.counters[prefix]
+	n.mu.RUnlock()
+	if ok {
+		return c
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if c, ok = n.counters[prefix]; !ok {
+		c = &prefixCounter{}
+		n.counters[prefix] = c
+	}
+	return c
+}
+
+// each 对key所属的所有计数器执行fn，包括root和key的各级命名空间
+func (n *namespaceCounters) each(key string, fn func(c *prefixCounter)) {
+	fn(n.root)
+	for i := 0; ; {
+		j := strings.Index(key[i:], namespaceSeparator)
+		if j < 0 {
+			return
+		}
+		i += j + len(namespaceSeparator)
+		fn(n.get(key[:i]))
+	}
+}
+
+func (n *namespaceCounters) onAdd(key string, old, val *Item) {
+	n.each(key, func(c *prefixCounter) {
+		c.add(old, val)
+	})
+}
+
+func (n *namespaceCounters) onRemove(key string, item *Item, reason EventOp) {
+	n.each(key, func(c *prefixCounter) {
+		c.remove(item, reason)
+	})
+}
+
+func (n *namespaceCounters) onFlush() {
+	n.root.flush()
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	for _, c := range n.counters {
+		c.flush()
+	}
+}
+
+// prefixCounter 统计命名空间中对象的数量、成本之和，以及被淘汰和过期删除的次数
+type prefixCounter struct {
+	// 需要原子操作的字段放在结构体开头以保证64位对齐
+	count           int64
+	cost            int64
+	evictions       uint64
+	memoryEvictions uint64
+	expirations     uint64
+}
+
+func (c *prefixCounter) add(old, val *Item) {
+	cost := val.Cost
+	if old == nil {
+		atomic.AddInt64(&c.count, 1)
+	} else {
+		cost -= old.Cost
+	}
+	if cost != 0 {
+		atomic.AddInt64(&c.cost, cost)
+	}
+}
+
+func (c *prefixCounter) remove(item *Item, reason EventOp) {
+	atomic.AddInt64(&c.count, -1)
+	if item.Cost != 0 {
+		atomic.AddInt64(&c.cost, -item.Cost)
+	}
+	switch reason {
+	case EventEvict:
+		atomic.AddUint64(&c.evictions, 1)
+	case eventEvictMemory:
+		atomic.AddUint64(&c.memoryEvictions, 1)
+	case EventExpire:
+		atomic.AddUint64(&c.expirations, 1)
+	}
+}
+
+func (c *prefixCounter) flush() {
+	atomic.StoreInt64(&c.count, 0)
+	atomic.StoreInt64(&c.cost, 0)
+}
+
+// escapeNamespace 转义命名空间名称中的分隔符，避免Namespace("a\x00b")与Namespace("a").Namespace("b")冲突
+func escapeNamespace(name string) string {
+	if !strings.ContainsAny(name, namespaceSeparator+namespaceEscape) {
+		return name
+	}
+	return namespaceEscaper.Replace(name)
+}
@@ -0,0 +1,199 @@
+package cache_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Nomango/go-cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamespace(t *testing.T) {
+	testFunc := func(t *testing.T, c cache.Cache) {
+		users := c.Namespace("users")
+		orders := c.Namespace("orders")
+		assert.Equal(t, c.Namespace("users"), users)
+
+		// 不同命名空间的key互不冲突
+		users.Set("1", "user1")
+		orders.Set("1", "order1")
+		c.Set("1", "root1")
+
+		value, _ := users.Get("1")
+		assert.Equal(t, value, "user1")
+		value, _ = orders.Get("1")
+		assert.Equal(t, value, "order1")
+		value, _ = c.Get("1")
+		assert.Equal(t, value, "root1")
+
+		// 独立的Len和Range，父缓存可以看到所有对象
+		users.Set("2", "user2")
+		assert.Equal(t, users.Len(), 2)
+		assert.Equal(t, orders.Len(), 1)
+		assert.Equal(t, c.Len(), 4)
+
+		keys := make(map[string]interface{})
+		users.Range(func(key string, value interface{}) bool {
+			keys[key] = value
+			return true
+		})
+		assert.Equal(t, keys, map[string]interface{}{"1": "user1", "2": "user2"})
+
+		// 批量操作
+		values := users.GetMulti([]string{"1", "2", "3"})
+		assert.Equal(t, values, map[string]interface{}{"1": "user1", "2": "user2"})
+
+		// 标签只在命名空间内生效
+		users.SetWithTags("3", "user3", cache.NoExpiration, "tag")
+		orders.SetWithTags("3", "order3", cache.NoExpiration, "tag")
+		assert.Equal(t, users.InvalidateTag("tag"), 1)
+		assert.Equal(t, orders.Len(), 2)
+
+		// 嵌套的命名空间
+		vip := users.Namespace("vip")
+		vip.Set("1", "vip1")
+		assert.Equal(t, vip.Len(), 1)
+		assert.Equal(t, users.Len(), 3)
+
+		// 独立的Flush
+		users.Flush()
+		assert.Equal(t, users.Len(), 0)
+		assert.Equal(t, vip.Len(), 0)
+		assert.Equal(t, orders.Len(), 2)
+		assert.Equal(t, c.Len(), 3)
+
+		// 独立的统计信息
+		rootStats, usersStats, ordersStats := c.Stats(), users.Stats(), orders.Stats()
+		_, _ = users.Get("1")
+		_, _ = orders.Get("1")
+		assert.Equal(t, users.Stats().Misses, usersStats.Misses+1)
+		assert.Equal(t, orders.Stats().Hits, ordersStats.Hits+1)
+		assert.Equal(t, c.Stats(), rootStats)
+
+		// 清理过期对象
+		orders.SetWithExpiration("4", "order4", time.Millisecond*100)
+		time.Sleep(time.Millisecond * 200)
+		assert.Equal(t, orders.Len(), 3)
+		c.ClearExpired()
+		assert.Equal(t, orders.Len(), 2)
+
+		// 清空父缓存
+		c.Flush()
+		assert.Equal(t, orders.Len(), 0)
+		assert.Equal(t, c.Len(), 0)
+	}
+
+	t.Run("Cache", func(t *testing.T) {
+		testFunc(t, cache.New())
+	})
+	t.Run("LRUCache", func(t *testing.T) {
+		testFunc(t, cache.NewWithOptions(&cache.Options{Capacity: 10}))
+	})
+	t.Run("CacheWithCallback", func(t *testing.T) {
+		testFunc(t, cache.NewWithOptions(&cache.Options{DeletedCallback: func(string, interface{}) {}}))
+	})
+}
+
+func TestNamespaceEscape(t *testing.T) {
+	c := cache.New()
+	a := c.Namespace("a\x00b")
+	b := c.Namespace("a").Namespace("b")
+	d := c.Namespace("a\x01").Namespace("b")
+
+	// 名称中的分隔符被转义，不会与嵌套的命名空间冲突
+	a.Set("key", 1)
+	b.Set("key", 2)
+	d.Set("key", 3)
+	value, _ := a.Get("key")
+	assert.Equal(t, value, 1)
+	value, _ = b.Get("key")
+	assert.Equal(t, value, 2)
+	assert.Equal(t, a.Len(), 1)
+	assert.Equal(t, b.Len(), 1)
+	assert.Equal(t, d.Len(), 1)
+	assert.Equal(t, c.Namespace("a").Len(), 1)
+	assert.Equal(t, c.Len(), 3)
+}
+
+func TestNamespaceCounters(t *testing.T) {
+	c := cache.New()
+	for i := 0; i < 100; i++ {
+		c.Namespace(strconv.Itoa(i)).Set("key", i)
+	}
+	ns := c.Namespace("1")
+	ns.Namespace("sub").Set("key", 1)
+
+	// 创建命名空间之前写入的对象也会被统计
+	c.Set("late\x00key", 1)
+	assert.Equal(t, c.Namespace("late").Len(), 1)
+
+	assert.Equal(t, c.Len(), 102)
+	assert.Equal(t, ns.Len(), 2)
+	assert.Equal(t, c.Namespace("2").Len(), 1)
+	ns.Flush()
+	assert.Equal(t, ns.Len(), 0)
+	assert.Equal(t, ns.Namespace("sub").Len(), 0)
+	assert.Equal(t, c.Len(), 100)
+}
+
+func TestNamespaceCapacity(t *testing.T) {
+	options := &cache.Options{
+		Capacity: 2,
+	}
+	c := cache.NewWithOptions(options)
+	ns1 := c.Namespace("ns1")
+	ns2 := c.Namespace("ns2")
+
+	ns1.Set("key", 1)
+	ns2.Set("key", 2)
+	// 共享容量，ns1的对象被淘汰
+	c.Set("key", 3)
+	assert.Equal(t, ns1.Len(), 0)
+	assert.Equal(t, ns2.Len(), 1)
+	assert.Equal(t, c.Len(), 2)
+	_, found := ns1.Get("key")
+	assert.Equal(t, found, false)
+}
+
+func TestStats(t *testing.T) {
+	c := cache.New()
+	c.Set("key", 1)
+
+	_, _ = c.Get("key")
+	_, _ = c.Get("none")
+	_ = c.GetMulti([]string{"key", "none", "none2"})
+
+	stats := c.Stats()
+	assert.Equal(t, stats.Hits, uint64(2))
+	assert.Equal(t, stats.Misses, uint64(3))
+	assert.Equal(t, stats.HitRatio(), 0.4)
+}
+
+func TestStatsRemovals(t *testing.T) {
+	c := cache.NewWithOptions(&cache.Options{Capacity: 2})
+	ns := c.Namespace("ns")
+
+	c.SetWithOptions("key1", 1, cache.ItemOptions{Cost: 10})
+	ns.SetWithOptions("key2", 2, cache.ItemOptions{Cost: 5, TTL: time.Millisecond})
+	assert.Equal(t, c.Stats().Cost, int64(15))
+	assert.Equal(t, ns.Stats().Cost, int64(5))
+
+	// 覆盖时更新成本
+	c.SetWithOptions("key1", 1, cache.ItemOptions{Cost: 20})
+	assert.Equal(t, c.Stats().Cost, int64(25))
+
+	time.Sleep(time.Millisecond * 10)
+	c.ClearExpired()
+	assert.Equal(t, c.Stats().Expirations, uint64(1))
+	assert.Equal(t, ns.Stats().Expirations, uint64(1))
+
+	ns.Set("key3", 3)
+	ns.Set("key4", 4)
+	assert.Equal(t, c.Stats().Evictions, uint64(1))
+	assert.Equal(t, ns.Stats().Evictions, uint64(0))
+	assert.Equal(t, c.Stats().Cost, int64(0))
+
+	c.Delete("key1")
+	assert.Equal(t, c.Stats().Evictions, uint64(1))
+}
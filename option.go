@@ -0,0 +1,187 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidOptions 缓存选项不合法
+var ErrInvalidOptions = errors.New("cache: invalid options")
+
+// Option 缓存选项，用于NewCache
+type Option func(*Options)
+
+// WithCapacity 设置容量，设置后将启用LRU
+func WithCapacity(capacity int) Option {
+	return func(o *Options) {
+		o.Capacity = capacity
+	}
+}
+
+// WithDefaultExpiration 设置默认的过期时长
+func WithDefaultExpiration(expiration time.Duration) Option {
+	return func(o *Options) {
+		o.DefaultExpiration = expiration
+	}
+}
+
+// WithCleanInterval 设置自动清理时间间隔
+func WithCleanInterval(interval time.Duration) Option {
+	return func(o *Options) {
+		o.CleanInterval = interval
+	}
+}
+
+// WithDeletedCallback 设置缓存对象被删除时的回调函数
+func WithDeletedCallback(cb DeletedCallback) Option {
+	return func(o *Options) {
+		o.DeletedCallback = cb
+	}
+}
+
+// WithCallbackWorkers 设置执行删除回调的工作协程数量和队列长度
+func WithCallbackWorkers(workers, queueSize int) Option {
+	return func(o *Options) {
+		o.CallbackWorkers = workers
+		o.CallbackQueueSize = queueSize
+	}
+}
+
+// WithErrorHandler 设置删除回调发生panic时的处理函数
+func WithErrorHandler(handler func(error)) Option {
+	return func(o *Options) {
+		o.ErrorHandler = handler
+	}
+}
+
+// WithExpirationJitter 设置过期时间的随机抖动上限
+func WithExpirationJitter(jitter time.Duration) Option {
+	return func(o *Options) {
+		o.ExpirationJitter = jitter
+	}
+}
+
+// WithKeyIndex 启用有序的key索引
+func WithKeyIndex() Option {
+	return func(o *Options) {
+		o.KeyIndex = true
+	}
+}
+
+// WithMaxPinned 设置LRU缓存中固定对象的数量上限
+func WithMaxPinned(maxPinned int) Option {
+	return func(o *Options) {
+		o.MaxPinned = maxPinned
+	}
+}
+
+// WithMemoryLimit 设置堆内存软上限和低水位，lowWatermark为0时使用软上限的90%
+func WithMemoryLimit(limit, lowWatermark uint64) Option {
+	return func(o *Options) {
+		o.MemoryLimit = limit
+		o.MemoryLowWatermark = lowWatermark
+	}
+}
+
+// WithMemoryCheckInterval 设置检查堆内存的时间间隔
+func WithMemoryCheckInterval(interval time.Duration) Option {
+	return func(o *Options) {
+		o.MemoryCheckInterval = interval
+	}
+}
+
+// WithMemoryGCRounds 设置每次内存检查最多执行的淘汰轮数，每轮淘汰后强制执行一次GC
+func WithMemoryGCRounds(rounds int) Option {
+	return func(o *Options) {
+		o.MemoryGCRounds = rounds
+	}
+}
+
+// WithClock 设置计算过期时间和访问时间使用的时钟
+func WithClock(clock Clock) Option {
+	return func(o *Options) {
+		o.Clock = clock
+	}
+}
+
+// WithHotKeys 启用热点key统计，记录capacity个热点key，访问次数每隔decayInterval减半
+func WithHotKeys(capacity int, decayInterval time.Duration) Option {
+	return func(o *Options) {
+		o.HotKeyCapacity = capacity
+		o.HotKeyDecayInterval = decayInterval
+	}
+}
+
+// WithInterceptors 添加拦截器，多次调用时按调用顺序追加
+func WithInterceptors(interceptors ...Interceptor) Option {
+	return func(o *Options) {
+		o.Interceptors = append(o.Interceptors, interceptors...)
+	}
+}
+
+// WithLoadObserver 设置GetOrLoadCtx每次调用loader后执行的观察函数
+func WithLoadObserver(observer LoadObserver) Option {
+	return func(o *Options) {
+		o.LoadObserver = observer
+	}
+}
+
+// NewCache 使用函数式选项新建缓存器，选项不合法时返回ErrInvalidOptions
+func NewCache(opts ...Option) (Cache, error) {
+	options := &Options{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(options)
+		}
+	}
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+	return NewWithOptions(options), nil
+}
+
+// Validate 检查选项是否合法，返回的错误包装了ErrInvalidOptions
+func (o *Options) Validate() error {
+	switch {
+	case o.DefaultExpiration < 0:
+		return invalidOptions("DefaultExpiration must not be negative, got %v", o.DefaultExpiration)
+	case o.CleanInterval < 0:
+		return invalidOptions("CleanInterval must not be negative, got %v", o.CleanInterval)
+	case o.Capacity < 0:
+		return invalidOptions("Capacity must not be negative, got %d", o.Capacity)
+	case o.ExpirationJitter < 0:
+		return invalidOptions("ExpirationJitter must not be negative, got %v", o.ExpirationJitter)
+	case o.CallbackWorkers < 0:
+		return invalidOptions("CallbackWorkers must not be negative, got %d", o.CallbackWorkers)
+	case o.CallbackQueueSize < 0:
+		return invalidOptions("CallbackQueueSize must not be negative, got %d", o.CallbackQueueSize)
+	case o.CallbackWorkers > 0 && o.DeletedCallback == nil:
+		return invalidOptions("CallbackWorkers requires DeletedCallback")
+	case o.CallbackQueueSize > 0 && o.CallbackWorkers == 0:
+		return invalidOptions("CallbackQueueSize requires CallbackWorkers")
+	case o.MaxPinned < 0:
+		return invalidOptions("MaxPinned must not be negative, got %d", o.MaxPinned)
+	case o.MaxPinned > 0 && o.Capacity == 0:
+		return invalidOptions("MaxPinned requires Capacity")
+	case o.MemoryCheckInterval < 0:
+		return invalidOptions("MemoryCheckInterval must not be negative, got %v", o.MemoryCheckInterval)
+	case o.MemoryGCRounds < 0:
+		return invalidOptions("MemoryGCRounds must not be negative, got %d", o.MemoryGCRounds)
+	case o.MemoryLimit == 0 && (o.MemoryLowWatermark > 0 || o.MemoryCheckInterval > 0 || o.MemoryGCRounds > 0):
+		return invalidOptions("MemoryLowWatermark, MemoryCheckInterval and MemoryGCRounds require MemoryLimit")
+	case o.MemoryLowWatermark > o.MemoryLimit:
+		return invalidOptions("MemoryLowWatermark (%d) must not exceed MemoryLimit (%d)", o.MemoryLowWatermark, o.MemoryLimit)
+	case o.HotKeyCapacity < 0:
+		return invalidOptions("HotKeyCapacity must not be negative, got %d", o.HotKeyCapacity)
+	case o.HotKeyDecayInterval < 0:
+		return invalidOptions("HotKeyDecayInterval must not be negative, got %v", o.HotKeyDecayInterval)
+	case o.HotKeyDecayInterval > 0 && o.HotKeyCapacity == 0:
+		return invalidOptions("HotKeyDecayInterval requires HotKeyCapacity")
+	}
+	return nil
+}
+
+func invalidOptions(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: "+format, append([]interface{}{ErrInvalidOptions}, args...)...)
+}
@@ -0,0 +1,103 @@
+package cache_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Nomango/go-cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock 手动推进的时钟
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestNewCache(t *testing.T) {
+	c, err := cache.NewCache(
+		cache.WithCapacity(2),
+		cache.WithDefaultExpiration(time.Minute),
+		cache.WithCleanInterval(time.Minute),
+		cache.WithDeletedCallback(func(string, interface{}) {}),
+		cache.WithCallbackWorkers(1, 10),
+	)
+	assert.Equal(t, err, nil)
+
+	c.Set("key1", 1)
+	c.Set("key2", 2)
+	c.Set("key3", 3)
+	assert.Equal(t, c.Len(), 2)
+	ttl, _ := c.TTL("key3")
+	assert.True(t, ttl > 0 && ttl <= time.Minute)
+}
+
+func TestNewCacheInvalid(t *testing.T) {
+	cases := [][]cache.Option{
+		{cache.WithDefaultExpiration(-time.Second)},
+		{cache.WithCleanInterval(-time.Second)},
+		{cache.WithCapacity(-1)},
+		{cache.WithExpirationJitter(-time.Second)},
+		{cache.WithCallbackWorkers(2, 0)},
+		{cache.WithDeletedCallback(func(string, interface{}) {}), cache.WithCallbackWorkers(0, 10)},
+		{cache.WithMaxPinned(1)},
+		{cache.WithMemoryCheckInterval(time.Second)},
+		{cache.WithMemoryLimit(100, 200)},
+		{cache.WithMemoryGCRounds(2)},
+		{cache.WithMemoryLimit(100, 0), cache.WithMemoryGCRounds(-1)},
+		{cache.WithHotKeys(-1, 0)},
+		{cache.WithHotKeys(0, time.Second)},
+	}
+	for _, opts := range cases {
+		c, err := cache.NewCache(opts...)
+		assert.Equal(t, c, nil)
+		assert.True(t, errors.Is(err, cache.ErrInvalidOptions), err)
+	}
+}
+
+func TestWithClock(t *testing.T) {
+	testFunc := func(t *testing.T, capacity int) {
+		clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+		c, err := cache.NewCache(cache.WithCapacity(capacity), cache.WithClock(clock))
+		assert.Equal(t, err, nil)
+
+		c.SetWithExpiration("key", 1, time.Hour)
+		ttl, _ := c.TTL("key")
+		assert.Equal(t, ttl, time.Hour)
+
+		info, _ := c.Inspect("key")
+		assert.Equal(t, info.CreatedTime, clock.Now())
+
+		clock.Advance(time.Minute * 59)
+		_, ok := c.Get("key")
+		assert.Equal(t, ok, true)
+		info, _ = c.Inspect("key")
+		assert.True(t, info.AccessedTime.Equal(clock.Now()))
+
+		// 计数器也使用自定义时钟
+		_, _ = c.IncrementInt64("counter", 1, time.Minute)
+		ttl, _ = c.TTL("counter")
+		assert.Equal(t, ttl, time.Minute)
+
+		clock.Advance(time.Minute * 2)
+		_, ok = c.Get("key")
+		assert.Equal(t, ok, false)
+		assert.Equal(t, c.Contains("counter"), false)
+	}
+	t.Run("Cache", func(t *testing.T) { testFunc(t, 0) })
+	t.Run("LRUCache", func(t *testing.T) { testFunc(t, 10) })
+}
@@ -0,0 +1,100 @@
+package cache
+
+import (
+	"container/list"
+	"sort"
+)
+
+// lruLists 按优先级分组的LRU链表，固定对象使用单独的链表
+// 淘汰时直接取最低优先级链表的尾部，开销与固定对象和高优先级对象的数量无关
+// 只在持有LRU缓存写锁时使用，不需要额外加锁
+type lruLists struct {
+	pinned *list.List
+	// normal 默认优先级的链表，未设置优先级时不需要查找lists
+	normal *list.List
+	// lists 各优先级的链表，包括默认优先级，链表为空时也不删除
+	lists map[int]*list.List
+	// levels lists中的优先级，从低到高排序
+	levels []int
+}
+
+func newLRULists() *lruLists {
+	l := &lruLists{}
+	l.init()
+	return l
+}
+
+func (l *lruLists) init() {
+	l.pinned = list.New()
+	l.normal = list.New()
+	l.lists = map[int]*list.List{0: l.normal}
+	l.levels = []int{0}
+}
+
+// of 返回对象所属的链表，优先级第一次出现时创建链表
+func (l *lruLists) of(item *Item) *list.List {
+	switch {
+	case item.Pinned:
+		return l.pinned
+	case item.Priority == 0:
+		return l.normal
+	}
+	lst, ok := l.lists[item.Priority]
+	if !ok {
+		lst = list.New()
+		l.lists[item.Priority] = lst
+		i := sort.SearchInts(l.levels, item.Priority)
+		l.levels = append(l.levels, 0)
+		copy(l.levels[i+1:], l.levels[i:])
+		l.levels[i] = item.Priority
+	}
+	return lst
+}
+
+func (l *lruLists) pushFront(node *lruNode) *list.Element {
+	return l.of(node.item).PushFront(node)
+}
+
+func (l *lruLists) moveToFront(elem *list.Element) {
+	l.of(elem.Value.(*lruNode).item).MoveToFront(elem)
+}
+
+func (l *lruLists) remove(elem *list.Element) {
+	l.of(elem.Value.(*lruNode).item).Remove(elem)
+}
+
+// pinnedLen 返回固定对象的数量
+func (l *lruLists) pinnedLen() int {
+	return l.pinned.Len()
+}
+
+// oldest 返回最低优先级中最久未访问的未固定对象，没有未固定对象时返回nil
+func (l *lruLists) oldest() *list.Element {
+	for _, priority := range l.levels {
+		if elem := l.lists[priority].Back(); elem != nil {
+			return elem
+		}
+	}
+	return nil
+}
+
+// each 按固定对象、优先级从高到低的顺序遍历，同一链表中最近访问的对象在前
+func (l *lruLists) each(fn func(node *lruNode) bool) {
+	if !eachNode(l.pinned, fn) {
+		return
+	}
+	for i := len(l.levels) - 1; i >= 0; i-- {
+		if !eachNode(l.lists[l.levels[i]], fn) {
+			return
+		}
+	}
+}
+
+func eachNode(lst *list.List, fn func(node *lruNode) bool) bool {
+	for elem := lst.Front(); elem != nil; elem = elem.Next() {
+		if !fn(elem.Value.(*lruNode)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var (
+	// ErrAlreadyRegistered 名称已被注册
+	ErrAlreadyRegistered = errors.New("cache: name already registered")
+	// ErrAlreadyInitialized 全局缓存已经初始化
+	ErrAlreadyInitialized = errors.New("cache: global cache already initialized")
+)
+
+// Registry 命名缓存的注册表
+type Registry struct {
+	mu     sync.RWMutex
+	caches map[string]Cache
+	keep   string // CloseAll时关闭但不移除的名称，只用于默认注册表中的全局缓存
+}
+
+// NewRegistry 新建注册表
+func NewRegistry() *Registry {
+	return &Registry{
+		caches: make(map[string]Cache),
+	}
+}
+
+// Register 使用选项新建缓存并注册，选项不合法或名称已被注册时返回错误
+func (r *Registry) Register(name string, options *Options) error {
+	if options != nil {
+		if err := options.Validate(); err != nil {
+			return err
+		}
+	}
+	_, err := r.register(name, func() Cache {
+		return NewWithOptions(options)
+	})
+	return err
+}
+
+// RegisterCache 注册已创建的缓存，名称已被注册时返回错误
+func (r *Registry) RegisterCache(name string, c Cache) error {
+	_, err := r.register(name, func() Cache {
+		return c
+	})
+	return err
+}
+
+// register 名称未被注册时注册create创建的缓存，已被注册时返回已注册的缓存和ErrAlreadyRegistered
+func (r *Registry) register(name string, create func() Cache) (Cache, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if c, ok := r.caches[name]; ok {
+		return c, fmt.Errorf("%w: %q", ErrAlreadyRegistered, name)
+	}
+	c := create()
+	r.caches[name] = c
+	return c, nil
+}
+
+// Lookup 查找已注册的缓存
+func (r *Registry) Lookup(name string) (Cache, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.caches[name]
+	return c, ok
+}
+
+// MustGet 查找已注册的缓存，未注册时panic
+func (r *Registry) MustGet(name string) Cache {
+	c, ok := r.Lookup(name)
+	if !ok {
+		panic(fmt.Sprintf("cache: %q is not registered", name))
+	}
+	return c
+}
+
+// Names 按字典序返回所有已注册的名称
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.caches))
+	for name := range r.caches {
+		names = append(names, name)
+	}
+	r.mu.RUnlock()
+	sort.Strings(names)
+	return names
+}
+
+// CloseAll 关闭所有已注册的缓存，并清空注册表
+func (r *Registry) CloseAll() {
+	r.mu.Lock()
+	caches := r.caches
+	r.caches = make(map[string]Cache)
+	if c, ok := caches[r.keep]; ok && r.keep != "" {
+		r.caches[r.keep] = c
+	}
+	r.mu.Unlock()
+
+	for _, c := range caches {
+		c.Close()
+	}
+}
+
+// DefaultRegistry 默认的注册表，全局缓存以DefaultName注册在其中
+// 全局缓存无法替换，所以CloseAll关闭全局缓存后仍然保留，与Global()保持一致
+var DefaultRegistry = &Registry{
+	caches: make(map[string]Cache),
+	keep:   DefaultName,
+}
+
+// Register 在默认注册表中新建并注册缓存
+func Register(name string, options *Options) error {
+	return DefaultRegistry.Register(name, options)
+}
+
+// RegisterCache 在默认注册表中注册已创建的缓存
+func RegisterCache(name string, c Cache) error {
+	return DefaultRegistry.RegisterCache(name, c)
+}
+
+// Lookup 在默认注册表中查找缓存
+func Lookup(name string) (Cache, bool) {
+	return DefaultRegistry.Lookup(name)
+}
+
+// MustGet 在默认注册表中查找缓存，未注册时panic
+func MustGet(name string) Cache {
+	return DefaultRegistry.MustGet(name)
+}
+
+// Names 返回默认注册表中所有已注册的名称
+func Names() []string {
+	return DefaultRegistry.Names()
+}
+
+// CloseAll 关闭默认注册表中所有的缓存，并清空注册表，已关闭的全局缓存仍然以DefaultName保留
+func CloseAll() {
+	DefaultRegistry.CloseAll()
+}
@@ -0,0 +1,67 @@
+package cache_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Nomango/go-cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	r := cache.NewRegistry()
+
+	assert.Equal(t, r.Register("users", &cache.Options{Capacity: 10}), nil)
+	assert.Equal(t, r.Register("orders", nil), nil)
+	assert.True(t, errors.Is(r.Register("users", nil), cache.ErrAlreadyRegistered))
+	assert.True(t, errors.Is(r.Register("invalid", &cache.Options{CleanInterval: -1}), cache.ErrInvalidOptions))
+
+	c, _ := cache.NewCache()
+	assert.Equal(t, r.RegisterCache("sessions", c), nil)
+	assert.True(t, errors.Is(r.RegisterCache("sessions", c), cache.ErrAlreadyRegistered))
+
+	assert.Equal(t, r.Names(), []string{"orders", "sessions", "users"})
+
+	users, ok := r.Lookup("users")
+	assert.Equal(t, ok, true)
+	users.Set("1", "user1")
+	assert.Equal(t, r.MustGet("users").Len(), 1)
+	assert.Equal(t, r.MustGet("sessions"), c)
+
+	_, ok = r.Lookup("unknown")
+	assert.Equal(t, ok, false)
+	assert.Panics(t, func() { r.MustGet("unknown") })
+
+	// 关闭所有缓存后注册表被清空，已关闭的缓存仍然可以读写
+	r.CloseAll()
+	assert.Equal(t, r.Names(), []string{})
+	v, ok := users.Get("1")
+	assert.Equal(t, ok, true)
+	assert.Equal(t, v, "user1")
+}
+
+func TestClose(t *testing.T) {
+	deleted := make(chan string, 1)
+	c := cache.NewWithOptions(&cache.Options{
+		CleanInterval:   time.Millisecond * 10,
+		DeletedCallback: func(key string, v interface{}) { deleted <- key },
+		CallbackWorkers: 1,
+	})
+	ns := c.Namespace("ns")
+	ns.Close()
+
+	// 命名空间的Close不影响父缓存
+	c.SetWithExpiration("key", 1, time.Millisecond)
+	assert.Equal(t, <-deleted, "key")
+
+	c.Close()
+	c.Close()
+
+	// 关闭后不再自动清理，删除回调同步执行
+	c.SetWithExpiration("key", 1, time.Millisecond)
+	time.Sleep(time.Millisecond * 50)
+	assert.Equal(t, c.Len(), 1)
+	c.Delete("key")
+	assert.Equal(t, <-deleted, "key")
+}
@@ -0,0 +1,205 @@
+package cache
+
+import (
+	"sync"
+)
+
+// scanBucketCount Scan游标的桶数量，游标即为下一个要遍历的桶的序号
+const scanBucketCount = 1 << 12
+
+var _ itemIndex = &scanIndex{}
+
+// scanIndex 将key按哈希值分到固定数量的桶中，使Scan可以分批遍历
+// 桶的数量固定不变，所以遍历期间一直存在的key一定会被返回，且只会返回一次
+type scanIndex struct {
+	buckets [scanBucketCount]scanBucket
+}
+
+type scanBucket struct {
+	mu   sync.Mutex
+	keys map[string]struct{}
+}
+
+func (s *scanIndex) bucket(key string) *scanBucket {
+	return &s.buckets[hashKey(key)%scanBucketCount]
+}
+
+func (s *scanIndex) onAdd(key string, old, _ *Item) {
+	if old != nil {
+		return
+	}
+	b := s.bucket(key)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.keys == nil {
+		b.keys = make(map[string]struct{})
+	}
+	b.keys[key] = struct{}{}
+}
+
+func (s *scanIndex) onRemove(key string, _ *Item, _ EventOp) {
+	b := s.bucket(key)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.keys, key)
+}
+
+func (s *scanIndex) onFlush() {
+	for i := range s.buckets {
+		b := &s.buckets[i]
+		b.mu.Lock()
+		b.keys = nil
+		b.mu.Unlock()
+	}
+}
+
+// scan 从cursor对应的桶开始遍历，遍历的key数量达到count后停止，返回满足match的key和下一次遍历的游标
+// 遍历完成时返回的游标为0
+func (s *scanIndex) scan(cursor uint64, count int, match func(key string) bool) ([]string, uint64) {
+	if count <= 0 {
+		count = 10
+	}
+
+	var keys []string
+	scanned := 0
+	for cursor < scanBucketCount {
+		b := &s.buckets[cursor]
+		b.mu.Lock()
+		for key := range b.keys {
+			if match == nil || match(key) {
+				keys = append(keys, key)
+			}
+		}
+		scanned += len(b.keys)
+		b.mu.Unlock()
+
+		cursor++
+		if scanned >= count {
+			break
+		}
+	}
+	if cursor >= scanBucketCount {
+		cursor = 0
+	}
+	return keys, cursor
+}
+
+// lazyScanIndex 第一次调用Scan时才创建的scanIndex，避免不使用Scan时的额外开销
+type lazyScanIndex struct {
+	once  sync.Once
+	index *scanIndex
+}
+
+func (l *lazyScanIndex) get(m baseItemMap) *scanIndex {
+	l.once.Do(func() {
+		l.index = &scanIndex{}
+		m.addIndex(l.index)
+	})
+	return l.index
+}
+
+// scanItems 分批遍历未过期对象的key，不会改变LRU顺序
+func scanItems(m baseItemMap, index *scanIndex, cursor uint64, count int, match func(key string) bool) ([]string, uint64) {
+	keys, next := index.scan(cursor, count, match)
+	alive := keys[:0]
+	for _, key := range keys {
+		if item, ok := m.PeekItem(key); ok && !item.isExpiredAt(m.now()) {
+			alive = append(alive, key)
+		}
+	}
+	return alive, next
+}
+
+// globMatch 判断key是否匹配glob模式，支持*、?、[abc]、[^a-z]和\转义，空模式匹配所有key
+func globMatch(pattern, key string) bool {
+	if pattern == "" {
+		return true
+	}
+
+	// 记录最近一次*的位置，匹配失败时回溯
+	p, k := 0, 0
+	starP, starK := -1, 0
+	for k < len(key) {
+		if p < len(pattern) {
+			switch pattern[p] {
+			case '*':
+				starP, starK = p, k
+				p++
+				continue
+			case '?':
+				p++
+				k++
+				continue
+			case '[':
+				if n, ok := matchClass(pattern[p:], key[k]); n > 0 {
+					if ok {
+						p += n
+						k++
+						continue
+					}
+				} else if key[k] == '[' {
+					// 不完整的[按普通字符处理
+					p++
+					k++
+					continue
+				}
+			case '\\':
+				if p+1 < len(pattern) && pattern[p+1] == key[k] {
+					p += 2
+					k++
+					continue
+				}
+			default:
+				if pattern[p] == key[k] {
+					p++
+					k++
+					continue
+				}
+			}
+		}
+		if starP < 0 {
+			return false
+		}
+		// 回溯，让*多匹配一个字符
+		starK++
+		p, k = starP+1, starK
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// matchClass 匹配[...]字符类，返回字符类的长度和是否匹配，字符类不完整时长度为0
+func matchClass(pattern string, c byte) (int, bool) {
+	i := 1
+	negate := false
+	if i < len(pattern) && pattern[i] == '^' {
+		negate = true
+		i++
+	}
+	matched := false
+	for first := true; i < len(pattern); first = false {
+		if pattern[i] == ']' && !first {
+			return i + 1, matched != negate
+		}
+		lo := pattern[i]
+		if lo == '\\' && i+1 < len(pattern) {
+			i++
+			lo = pattern[i]
+		}
+		hi := lo
+		if i+2 < len(pattern) && pattern[i+1] == '-' && pattern[i+2] != ']' {
+			hi = pattern[i+2]
+			i += 2
+		}
+		if lo > hi {
+			lo, hi = hi, lo
+		}
+		if lo <= c && c <= hi {
+			matched = true
+		}
+		i++
+	}
+	return 0, false
+}
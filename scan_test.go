@@ -0,0 +1,120 @@
+package cache_test
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/Nomango/go-cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func scanAll(c cache.Cache, match string, count int) []string {
+	var all []string
+	cursor := uint64(0)
+	for {
+		keys, next := c.Scan(cursor, match, count)
+		all = append(all, keys...)
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+	sort.Strings(all)
+	return all
+}
+
+func TestScan(t *testing.T) {
+	testFunc := func(t *testing.T, c cache.Cache) {
+		assert.Equal(t, len(scanAll(c, "", 10)), 0)
+
+		var expected []string
+		for i := 0; i < 1000; i++ {
+			key := fmt.Sprintf("key%d", i)
+			c.Set(key, i)
+			expected = append(expected, key)
+		}
+		sort.Strings(expected)
+
+		// 分批遍历
+		keys, next := c.Scan(0, "", 10)
+		assert.NotEqual(t, next, uint64(0))
+		assert.True(t, len(keys) < 1000)
+		assert.Equal(t, scanAll(c, "", 10), expected)
+		assert.Equal(t, scanAll(c, "*", 100), expected)
+
+		// glob匹配
+		assert.Equal(t, scanAll(c, "key99?", 10), []string{"key990", "key991", "key992", "key993", "key994", "key995", "key996", "key997", "key998", "key999"})
+		assert.Equal(t, scanAll(c, "key1[0-2]", 10), []string{"key10", "key11", "key12"})
+		assert.Equal(t, scanAll(c, "key[^0-8]", 10), []string{"key9"})
+		assert.Equal(t, scanAll(c, "*99*9", 10), []string{"key999"})
+		assert.Equal(t, len(scanAll(c, "nomatch*", 10)), 0)
+
+		// 命名空间
+		ns := c.Namespace("ns")
+		ns.Set("key1", 1)
+		ns.Set("other", 2)
+		assert.Equal(t, scanAll(ns, "key*", 10), []string{"key1"})
+		assert.Equal(t, scanAll(ns, "", 10), []string{"key1", "other"})
+
+		// 删除后不再返回
+		c.Delete("key1")
+		assert.Equal(t, scanAll(c, "key1", 10), []string(nil))
+		c.Flush()
+		assert.Equal(t, len(scanAll(c, "", 10)), 0)
+	}
+
+	t.Run("Cache", func(t *testing.T) {
+		testFunc(t, cache.New())
+	})
+	t.Run("LRUCache", func(t *testing.T) {
+		testFunc(t, cache.NewWithOptions(&cache.Options{Capacity: 2000}))
+	})
+}
+
+func TestScanConcurrent(t *testing.T) {
+	c := cache.New()
+	for i := 0; i < 1000; i++ {
+		c.Set(fmt.Sprintf("stable%d", i), i)
+	}
+
+	// 遍历期间并发写入和删除其他对象
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			key := fmt.Sprintf("temp%d", i%500)
+			c.Set(key, i)
+			c.Delete(fmt.Sprintf("temp%d", (i+250)%500))
+		}
+	}()
+
+	// 遍历期间一直存在的对象只会返回一次
+	seen := make(map[string]int)
+	cursor := uint64(0)
+	for {
+		keys, next := c.Scan(cursor, "stable*", 20)
+		for _, key := range keys {
+			seen[key]++
+		}
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+	close(stop)
+	wg.Wait()
+
+	assert.Equal(t, len(seen), 1000)
+	for key, count := range seen {
+		assert.Equal(t, count, 1, key)
+	}
+}
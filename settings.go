@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrNotBounded 无容量上限的缓存不能修改容量
+	ErrNotBounded = errors.New("cache: cannot resize a cache without capacity")
+	// ErrInvalidCapacity 容量必须大于0
+	ErrInvalidCapacity = errors.New("cache: capacity must be positive")
+)
+
+// settings 可以在运行时修改的配置，命名空间与父缓存共享
+type settings struct {
+	// defaultExpiration 需要原子操作，放在结构体开头以保证64位对齐
+	defaultExpiration int64
+
+	mu      sync.Mutex
+	root    *cache
+	cleaner *cleaner // 未启动自动清理时为nil
+	closed  bool     // 缓存关闭后不再启动cleaner
+}
+
+func newSettings(root *cache, options *Options) *settings {
+	s := &settings{
+		defaultExpiration: int64(options.DefaultExpiration),
+		root:              root,
+	}
+	s.setCleanInterval(options.CleanInterval)
+	return s
+}
+
+func (s *settings) getDefaultExpiration() time.Duration {
+	return time.Duration(atomic.LoadInt64(&s.defaultExpiration))
+}
+
+func (s *settings) setDefaultExpiration(expiration time.Duration) {
+	atomic.StoreInt64(&s.defaultExpiration, int64(expiration))
+}
+
+// setCleanInterval 停止正在运行的cleaner，并按新的时间间隔重新启动，缓存关闭后不做任何事
+func (s *settings) setCleanInterval(interval time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+	s.stopCleaner()
+	if interval > 0 {
+		s.cleaner = newCleaner(s.root, interval)
+	}
+}
+
+// close 停止cleaner，之后不能再启动
+func (s *settings) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	s.stopCleaner()
+}
+
+// stopCleaner 停止正在运行的cleaner，调用前需持有锁
+func (s *settings) stopCleaner() {
+	if s.cleaner != nil {
+		s.cleaner.Stop()
+		s.cleaner = nil
+	}
+}
+
+// resizer 可以修改容量的ItemMap
+type resizer interface {
+	resize(capacity int) error
+}
+
+func (c *cache) Resize(capacity int) error {
+	if capacity <= 0 {
+		return ErrInvalidCapacity
+	}
+	if r, ok := c.ItemMap.(resizer); ok {
+		return r.resize(capacity)
+	}
+	return ErrNotBounded
+}
+
+func (c *cache) SetDefaultExpiration(expiration time.Duration) {
+	c.settings.setDefaultExpiration(expiration)
+}
+
+func (c *cache) SetCleanInterval(interval time.Duration) {
+	c.settings.setCleanInterval(interval)
+}
@@ -0,0 +1,67 @@
+package cache
+
+import "sync/atomic"
+
+// Stats 缓存的统计信息
+type Stats struct {
+	// Hits 命中次数
+	Hits uint64
+	// Misses 未命中次数
+	Misses uint64
+	// DroppedEvents 因订阅者的缓冲区已满而丢弃的事件数量
+	DroppedEvents uint64
+	// Evictions 因容量限制被淘汰的对象数量
+	Evictions uint64
+	// MemoryEvictions 因内存压力被淘汰的对象数量
+	MemoryEvictions uint64
+	// Expirations 过期被删除的对象数量
+	Expirations uint64
+	// Cost 当前所有对象的成本之和
+	Cost int64
+}
+
+// HitRatio 命中率
+func (s Stats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// statsCounter 统计信息计数器，并发安全
+type statsCounter struct {
+	hits          uint64
+	misses        uint64
+	droppedEvents uint64
+}
+
+func (s *statsCounter) record(hit bool) {
+	if hit {
+		atomic.AddUint64(&s.hits, 1)
+	} else {
+		atomic.AddUint64(&s.misses, 1)
+	}
+}
+
+func (s *statsCounter) recordMulti(hits, misses int) {
+	atomic.AddUint64(&s.hits, uint64(hits))
+	atomic.AddUint64(&s.misses, uint64(misses))
+}
+
+func (s *statsCounter) load() Stats {
+	return Stats{
+		Hits:          atomic.LoadUint64(&s.hits),
+		Misses:        atomic.LoadUint64(&s.misses),
+		DroppedEvents: atomic.LoadUint64(&s.droppedEvents),
+	}
+}
+
+func (c *cache) Stats() Stats {
+	stats := c.stats.load()
+	stats.Evictions = atomic.LoadUint64(&c.usage.evictions)
+	stats.MemoryEvictions = atomic.LoadUint64(&c.usage.memoryEvictions)
+	stats.Expirations = atomic.LoadUint64(&c.usage.expirations)
+	stats.Cost = atomic.LoadInt64(&c.usage.cost)
+	return stats
+}
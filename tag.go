@@ -0,0 +1,76 @@
+package cache
+
+import "sync"
+
+var _ itemIndex = &tagIndex{}
+
+// tagIndex 标签到key的反向索引
+type tagIndex struct {
+	mu   sync.RWMutex
+	tags map[string]map[string]struct{}
+}
+
+func newTagIndex() *tagIndex {
+	return &tagIndex{
+		tags: make(map[string]map[string]struct{}),
+	}
+}
+
+// keys 返回包含标签的所有key
+func (t *tagIndex) keys(tag string) []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	keys := make([]string, 0, len(t.tags[tag]))
+	for key := range t.tags[tag] {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (t *tagIndex) onAdd(key string, old, val *Item) {
+	if (old == nil || len(old.Tags) == 0) && len(val.Tags) == 0 {
+		// 没有标签时不加锁
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if old != nil {
+		t.unlink(key, old.Tags)
+	}
+	for _, tag := range val.Tags {
+		keys, ok := t.tags[tag]
+		if !ok {
+			keys = make(map[string]struct{})
+			t.tags[tag] = keys
+		}
+		keys[key] = struct{}{}
+	}
+}
+
+func (t *tagIndex) onRemove(key string, item *Item, _ EventOp) {
+	if len(item.Tags) == 0 {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.unlink(key, item.Tags)
+}
+
+func (t *tagIndex) onFlush() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tags = make(map[string]map[string]struct{})
+}
+
+func (t *tagIndex) unlink(key string, tags []string) {
+	for _, tag := range tags {
+		keys := t.tags[tag]
+		delete(keys, key)
+		if len(keys) == 0 {
+			delete(t.tags, tag)
+		}
+	}
+}
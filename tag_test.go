@@ -0,0 +1,91 @@
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Nomango/go-cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTag(t *testing.T) {
+	testFunc := func(t *testing.T, c cache.Cache) {
+		c.SetWithTags("user:1:profile", 1, cache.NoExpiration, "user:1")
+		c.SetWithTags("user:1:orders", 2, cache.NoExpiration, "user:1", "orders")
+		c.SetWithTags("user:2:orders", 3, cache.NoExpiration, "user:2", "orders")
+		c.Set("other", 4)
+
+		item, _ := c.GetItem("user:1:orders")
+		assert.Equal(t, item.HasTag("orders"), true)
+		assert.Equal(t, item.HasTag("user:2"), false)
+
+		// 删除包含标签的所有对象
+		assert.Equal(t, c.InvalidateTag("user:1"), 2)
+		assert.Equal(t, c.Len(), 2)
+		_, found := c.Get("user:1:orders")
+		assert.Equal(t, found, false)
+		assert.Equal(t, c.InvalidateTag("user:1"), 0)
+
+		// 覆盖对象后，旧的标签失效
+		c.Set("user:2:orders", 5)
+		assert.Equal(t, c.InvalidateTag("orders"), 0)
+		assert.Equal(t, c.Len(), 2)
+
+		// 修改对象时保留标签
+		c.SetWithTags("key", 1, cache.NoExpiration, "tag")
+		c.Update("key", func(old interface{}, exists bool) (interface{}, bool) {
+			return old.(int) + 1, true
+		})
+		c.Expire("key", time.Hour)
+		assert.Equal(t, c.InvalidateTag("tag"), 1)
+
+		// 删除对象后，标签失效
+		c.SetWithTags("key", 1, cache.NoExpiration, "tag")
+		c.Delete("key")
+		c.Set("key", 1)
+		assert.Equal(t, c.InvalidateTag("tag"), 0)
+
+		// 清理过期对象后，标签失效
+		c.SetWithTags("key", 1, time.Millisecond*100, "tag")
+		time.Sleep(time.Millisecond * 200)
+		c.ClearExpired()
+		c.Set("key", 1)
+		assert.Equal(t, c.InvalidateTag("tag"), 0)
+
+		// 清空缓存后，标签失效
+		c.SetWithTags("key", 1, cache.NoExpiration, "tag")
+		c.Flush()
+		c.Set("key", 1)
+		assert.Equal(t, c.InvalidateTag("tag"), 0)
+		assert.Equal(t, c.Len(), 1)
+	}
+
+	t.Run("Cache", func(t *testing.T) {
+		testFunc(t, cache.New())
+	})
+	t.Run("LRUCache", func(t *testing.T) {
+		testFunc(t, cache.NewWithOptions(&cache.Options{Capacity: 10}))
+	})
+	t.Run("CacheWithCallback", func(t *testing.T) {
+		testFunc(t, cache.NewWithOptions(&cache.Options{DeletedCallback: func(string, interface{}) {}}))
+	})
+}
+
+func TestLRUTagEviction(t *testing.T) {
+	options := &cache.Options{
+		Capacity: 2,
+	}
+	c := cache.NewWithOptions(options)
+
+	c.SetWithTags("key1", 1, cache.NoExpiration, "tag")
+	c.SetWithTags("key2", 2, cache.NoExpiration, "tag")
+	// key1被淘汰
+	c.Set("key3", 3)
+	_, found := c.Get("key1")
+	assert.Equal(t, found, false)
+
+	// 淘汰后重新写入不带标签的key1
+	c.Set("key1", 1)
+	assert.Equal(t, c.InvalidateTag("tag"), 0)
+	assert.Equal(t, c.Len(), 2)
+}